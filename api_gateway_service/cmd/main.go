@@ -5,7 +5,11 @@ import (
 	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/application"
 	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/config"
 	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/clients"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/health"
 	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/metrics"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/ratelimit"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/tracing"
 	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/server"
 	"github.com/joho/godotenv"
 
@@ -29,10 +33,15 @@ func main() {
 	addOpts := fx.Options(
 		fx.Provide(
 			config.NewConfig,
+			tracing.NewTracerProvider,
 			clients.NewGRPCClients,
 			application.NewGatewayApp,
+			health.NewChecker,
+			server.NewGatewayMux,
 			server.NewServer,
 		),
+		metrics.Module,
+		ratelimit.Module,
 		fx.Invoke(
 			server.RunServer,
 		),
@@ -3,24 +3,53 @@ package application
 import (
 	"context"
 	"encoding/json"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/config"
 	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/clients"
 	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/logger"
 	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/models"
 	pb "github.com/grigorovskiiy/soa-hse/protos"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
 	"io"
 	"net/http"
 	"strconv"
 )
 
 type GatewayApp struct {
-	GRPCClients *clients.GRPCClients
+	GRPCClients  *clients.GRPCClients
+	statsMetrics map[string]statsMetric
+	cfg          *config.Config
 }
 
-func NewGatewayApp(GRPCClients *clients.GRPCClients) *GatewayApp {
+func NewGatewayApp(GRPCClients *clients.GRPCClients, cfg *config.Config) *GatewayApp {
 	return &GatewayApp{
-		GRPCClients: GRPCClients,
+		GRPCClients:  GRPCClients,
+		statsMetrics: newStatsMetrics(GRPCClients.StatisticServiceClient),
+		cfg:          cfg,
+	}
+}
+
+// withUserID passes the user checked by AuthMiddleware to the backend services.
+func withUserID(r *http.Request) context.Context {
+	return metadata.AppendToOutgoingContext(r.Context(), "user_id", r.Header.Get("UserID"))
+}
+
+func httpStatus(err error) int {
+	switch status.Code(err) {
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
 	}
 }
 
@@ -41,7 +70,7 @@ func writeRes(w http.ResponseWriter, code int, val any) {
 // @Failure		 400 {string} string
 // @Router       /register [post]
 func (a *GatewayApp) Register(w http.ResponseWriter, r *http.Request) {
-	logger.Logger.Info("request proxied", "path", "/register")
+	logger.FromContext(r.Context()).Info("request proxied", "path", "/register")
 }
 
 // Login godoc
@@ -53,9 +82,10 @@ func (a *GatewayApp) Register(w http.ResponseWriter, r *http.Request) {
 // @Param 		 user body models.GetLoginRequest true "Войти в систему"
 // @Success      200  {string} string
 // @Failure 	 400 {string} string
+// @Failure 	 429 {string} string
 // @Router       /login [post]
 func (a *GatewayApp) Login(w http.ResponseWriter, r *http.Request) {
-	logger.Logger.Info("request proxied", "path", "/login")
+	logger.FromContext(r.Context()).Info("request proxied", "path", "/login")
 }
 
 // UpdateUserInfo godoc
@@ -72,7 +102,7 @@ func (a *GatewayApp) Login(w http.ResponseWriter, r *http.Request) {
 // @Failure 	 500
 // @Router       /update_user_info [put]
 func (a *GatewayApp) UpdateUserInfo(w http.ResponseWriter, r *http.Request) {
-	logger.Logger.Info("request proxied", "path", "/update_user_info")
+	logger.FromContext(r.Context()).Info("request proxied", "path", "/update_user_info")
 }
 
 // GetUserInfo godoc
@@ -88,7 +118,7 @@ func (a *GatewayApp) UpdateUserInfo(w http.ResponseWriter, r *http.Request) {
 // @Failure 	 500
 // @Router       /get_user_info [get]
 func (a *GatewayApp) GetUserInfo(w http.ResponseWriter, r *http.Request) {
-	logger.Logger.Info("request proxied", "path", "/get_user_info")
+	logger.FromContext(r.Context()).Info("request proxied", "path", "/get_user_info")
 }
 
 // GetPost godoc
@@ -104,7 +134,7 @@ func (a *GatewayApp) GetUserInfo(w http.ResponseWriter, r *http.Request) {
 // @Failure 	 500 {string} string
 // @Router       /get_post [get]
 func (a *GatewayApp) GetPost(w http.ResponseWriter, r *http.Request) {
-	logger := logger.Logger.With("path", r.URL.Path, "method", r.Method)
+	logger := logger.FromContext(r.Context()).With("path", r.URL.Path, "method", r.Method)
 
 	query := r.URL.Query()
 	postIDStr := query.Get("post_id")
@@ -127,7 +157,7 @@ func (a *GatewayApp) GetPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := metadata.AppendToOutgoingContext(context.Background(), "user_id", userID)
+	ctx := metadata.AppendToOutgoingContext(r.Context(), "user_id", userID)
 
 	res, err := a.GRPCClients.PostsServiceClient.GetPost(ctx, &pb.PostID{PostId: int32(postID)})
 	if err != nil {
@@ -153,7 +183,7 @@ func (a *GatewayApp) GetPost(w http.ResponseWriter, r *http.Request) {
 // @Failure 	 500 {string} string
 // @Router       /get_post_list [get]
 func (a *GatewayApp) GetPostList(w http.ResponseWriter, r *http.Request) {
-	logger := logger.Logger.With("path", r.URL.Path, "method", r.Method)
+	logger := logger.FromContext(r.Context()).With("path", r.URL.Path, "method", r.Method)
 
 	query := r.URL.Query()
 	pageStr := query.Get("page")
@@ -181,7 +211,7 @@ func (a *GatewayApp) GetPostList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := metadata.AppendToOutgoingContext(context.Background(), "user_id", userID)
+	ctx := metadata.AppendToOutgoingContext(r.Context(), "user_id", userID)
 	res, err := a.GRPCClients.PostsServiceClient.GetPostList(ctx, &pb.PaginatedListRequest{Page: int32(page), PageSize: int32(pageSize)})
 	if err != nil {
 		logger.Error("rpc request GetPostList", "error", status.Convert(err).Message())
@@ -205,7 +235,7 @@ func (a *GatewayApp) GetPostList(w http.ResponseWriter, r *http.Request) {
 // @Failure 	 500 {string} string
 // @Router       /create_post [post]
 func (a *GatewayApp) CreatePost(w http.ResponseWriter, r *http.Request) {
-	logger := logger.Logger.With("path", r.URL.Path, "method", r.Method)
+	logger := logger.FromContext(r.Context()).With("path", r.URL.Path, "method", r.Method)
 
 	d, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -229,7 +259,7 @@ func (a *GatewayApp) CreatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := metadata.AppendToOutgoingContext(context.Background(), "user_id", userID)
+	ctx := metadata.AppendToOutgoingContext(r.Context(), "user_id", userID)
 	_, err = a.GRPCClients.PostsServiceClient.CreatePost(ctx, req.ToPostsProto())
 	if err != nil {
 		logger.Error("grpc request CreatePost error", "error", status.Convert(err))
@@ -253,7 +283,7 @@ func (a *GatewayApp) CreatePost(w http.ResponseWriter, r *http.Request) {
 // @Failure 	 500 {string} string
 // @Router       /delete_post [delete]
 func (a *GatewayApp) DeletePost(w http.ResponseWriter, r *http.Request) {
-	logger := logger.Logger.With("path", r.URL.Path, "method", r.Method)
+	logger := logger.FromContext(r.Context()).With("path", r.URL.Path, "method", r.Method)
 
 	d, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -276,7 +306,7 @@ func (a *GatewayApp) DeletePost(w http.ResponseWriter, r *http.Request) {
 		writeRes(w, http.StatusBadRequest, "user_id is empty")
 		return
 	}
-	ctx := metadata.AppendToOutgoingContext(context.Background(), "user_id", userID)
+	ctx := metadata.AppendToOutgoingContext(r.Context(), "user_id", userID)
 	_, err = a.GRPCClients.PostsServiceClient.DeletePost(ctx, req.ToPostsProto())
 	if err != nil {
 		logger.Error("grpc request DeletePost error", "error", status.Convert(err).Message())
@@ -300,7 +330,7 @@ func (a *GatewayApp) DeletePost(w http.ResponseWriter, r *http.Request) {
 // @Failure 	 500 {string} string
 // @Router       /update_post [put]
 func (a *GatewayApp) UpdatePost(w http.ResponseWriter, r *http.Request) {
-	logger := logger.Logger.With("path", r.URL.Path, "method", r.Method)
+	logger := logger.FromContext(r.Context()).With("path", r.URL.Path, "method", r.Method)
 
 	d, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -323,10 +353,10 @@ func (a *GatewayApp) UpdatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := metadata.AppendToOutgoingContext(context.Background(), "user_id", userID)
+	ctx := metadata.AppendToOutgoingContext(r.Context(), "user_id", userID)
 	_, err = a.GRPCClients.PostsServiceClient.UpdatePost(ctx, req.ToPostsProto())
 	if err != nil {
-		logger.Error("error grpc request UpdatePost", status.Convert(err).Message())
+		logger.Error("error grpc request UpdatePost", "error", status.Convert(err).Message())
 		writeRes(w, http.StatusInternalServerError, status.Convert(err).Message())
 		return
 	}
@@ -347,7 +377,7 @@ func (a *GatewayApp) UpdatePost(w http.ResponseWriter, r *http.Request) {
 // @Failure 	 500 {string} string
 // @Router       /post_comment [post]
 func (a *GatewayApp) PostComment(w http.ResponseWriter, r *http.Request) {
-	logger := logger.Logger.With("path", r.URL.Path, "method", r.Method)
+	logger := logger.FromContext(r.Context()).With("path", r.URL.Path, "method", r.Method)
 
 	d, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -371,10 +401,10 @@ func (a *GatewayApp) PostComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := metadata.AppendToOutgoingContext(context.Background(), "user_id", userID)
+	ctx := metadata.AppendToOutgoingContext(r.Context(), "user_id", userID)
 	_, err = a.GRPCClients.PostsServiceClient.PostComment(ctx, req.ToPostsProto())
 	if err != nil {
-		logger.Error("error  grpc request PostComment", status.Convert(err).Message())
+		logger.Error("error  grpc request PostComment", "error", status.Convert(err).Message())
 		writeRes(w, http.StatusInternalServerError, status.Convert(err).Message())
 		return
 	}
@@ -395,7 +425,7 @@ func (a *GatewayApp) PostComment(w http.ResponseWriter, r *http.Request) {
 // @Failure 	 500 {string} string
 // @Router       /post_like [post]
 func (a *GatewayApp) PostLike(w http.ResponseWriter, r *http.Request) {
-	logger := logger.Logger.With("path", r.URL.Path, "method", r.Method)
+	logger := logger.FromContext(r.Context()).With("path", r.URL.Path, "method", r.Method)
 
 	d, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -418,10 +448,10 @@ func (a *GatewayApp) PostLike(w http.ResponseWriter, r *http.Request) {
 		writeRes(w, http.StatusBadRequest, "user_id is empty")
 	}
 
-	ctx := metadata.AppendToOutgoingContext(context.Background(), "user_id", userID)
+	ctx := metadata.AppendToOutgoingContext(r.Context(), "user_id", userID)
 	_, err = a.GRPCClients.PostsServiceClient.PostLike(ctx, req.ToPostsProto())
 	if err != nil {
-		logger.Error("error grpc request PostLike", status.Convert(err).Message())
+		logger.Error("error grpc request PostLike", "error", status.Convert(err).Message())
 		writeRes(w, http.StatusInternalServerError, status.Convert(err).Message())
 		return
 	}
@@ -442,7 +472,7 @@ func (a *GatewayApp) PostLike(w http.ResponseWriter, r *http.Request) {
 // @Failure 	 500 {string} string
 // @Router       /post_view [post]
 func (a *GatewayApp) PostView(w http.ResponseWriter, r *http.Request) {
-	logger := logger.Logger.With("path", r.URL.Path, "method", r.Method)
+	logger := logger.FromContext(r.Context()).With("path", r.URL.Path, "method", r.Method)
 
 	d, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -466,10 +496,10 @@ func (a *GatewayApp) PostView(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := metadata.AppendToOutgoingContext(context.Background(), "user_id", userID)
+	ctx := metadata.AppendToOutgoingContext(r.Context(), "user_id", userID)
 	_, err = a.GRPCClients.PostsServiceClient.PostView(ctx, req.ToPostsProto())
 	if err != nil {
-		logger.Error("error grpc request PostView", status.Convert(err).Message())
+		logger.Error("error grpc request PostView", "error", status.Convert(err).Message())
 		writeRes(w, http.StatusInternalServerError, status.Convert(err).Message())
 		return
 	}
@@ -491,7 +521,7 @@ func (a *GatewayApp) PostView(w http.ResponseWriter, r *http.Request) {
 // @Failure 	 500 {string} string
 // @Router       /get_comment_list [get]
 func (a *GatewayApp) GetCommentList(w http.ResponseWriter, r *http.Request) {
-	logger := logger.Logger.With("path", r.URL.Path, "method", r.Method)
+	logger := logger.FromContext(r.Context()).With("path", r.URL.Path, "method", r.Method)
 
 	query := r.URL.Query()
 	pageStr := query.Get("page")
@@ -519,10 +549,10 @@ func (a *GatewayApp) GetCommentList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := metadata.AppendToOutgoingContext(context.Background(), "user_id", userID)
+	ctx := metadata.AppendToOutgoingContext(r.Context(), "user_id", userID)
 	res, err := a.GRPCClients.PostsServiceClient.GetCommentList(ctx, &pb.PaginatedListRequest{Page: int32(page), PageSize: int32(pageSize)})
 	if err != nil {
-		logger.Error("error grpc request GetCommentList", status.Convert(err).Message())
+		logger.Error("error grpc request GetCommentList", "error", status.Convert(err).Message())
 		writeRes(w, http.StatusInternalServerError, status.Convert(err).Message())
 		return
 	}
@@ -534,235 +564,197 @@ func (a *GatewayApp) GetCommentList(w http.ResponseWriter, r *http.Request) {
 // @Summary      Получить количество просмотров по посту
 // @Description  Получить количество просмотров по посту
 // @Tags         Statistic
+// @Security BearerAuth
 // @Produce      json
 // @Param 		 post_id query int true "ID поста"
 // @Success      200  {object} models.CountResponse
 // @Failure 	 400 {string} string
 // @Failure 	 401  {string} string
+// @Failure 	 403  {string} string
 // @Failure 	 500 {string} string
 // @Router       /get_views_count [get]
 func (a *GatewayApp) GetViewsCount(w http.ResponseWriter, r *http.Request) {
-	logger := logger.Logger.With("path", r.URL.Path, "method", r.Method)
-
-	query := r.URL.Query()
-	postIdStr := query.Get("post_id")
-
-	postId, err := strconv.Atoi(postIdStr)
-	if err != nil {
-		logger.Error("postId is empty")
-		writeRes(w, http.StatusBadRequest, "postId is empty")
-	}
-
-	req := models.PostID{PostID: postId}
-
-	res, err := a.GRPCClients.StatisticServiceClient.GetViewsCount(r.Context(), req.ToStatisticProto())
-	if err != nil {
-		logger.Error("error grpc request GetViewsCount", status.Convert(err).Message())
-		writeRes(w, http.StatusInternalServerError, status.Convert(err).Message())
-		return
-	}
-
-	writeRes(w, http.StatusOK, models.FromProtoCountResponse(res))
+	a.writeStats(w, r, "views", countStat)
 }
 
 // GetLikesCount godoc
 // @Summary      Получить количество лайков по посту
 // @Description  Получить количество лайков по посту
 // @Tags         Statistic
+// @Security BearerAuth
 // @Produce      json
 // @Param 		 post_id query int true "ID поста"
 // @Success      200  {object} models.CountResponse
 // @Failure 	 400 {string} string
 // @Failure 	 401  {string} string
+// @Failure 	 403  {string} string
 // @Failure 	 500 {string} string
 // @Router       /get_likes_count [get]
 func (a *GatewayApp) GetLikesCount(w http.ResponseWriter, r *http.Request) {
-	logger := logger.Logger.With("path", r.URL.Path, "method", r.Method)
-
-	query := r.URL.Query()
-	postIdStr := query.Get("post_id")
-
-	postId, err := strconv.Atoi(postIdStr)
-	if err != nil {
-		logger.Error("postId is empty")
-		writeRes(w, http.StatusBadRequest, "postId is empty")
-	}
-
-	req := models.PostID{PostID: postId}
-
-	res, err := a.GRPCClients.StatisticServiceClient.GetLikesCount(r.Context(), req.ToStatisticProto())
-	if err != nil {
-		logger.Error("error grpc request GetLikesCount", status.Convert(err).Message())
-		writeRes(w, http.StatusInternalServerError, status.Convert(err).Message())
-		return
-	}
-
-	writeRes(w, http.StatusOK, models.FromProtoCountResponse(res))
+	a.writeStats(w, r, "likes", countStat)
 }
 
 // GetCommentsCount godoc
 // @Summary      Получить количество комментариев по посту
 // @Description  Получить количество комментариев по посту
 // @Tags         Statistic
+// @Security BearerAuth
 // @Produce      json
 // @Param 		 post_id query int true "ID поста"
 // @Success      200  {object} models.CountResponse
 // @Failure 	 400 {string} string
 // @Failure 	 401  {string} string
+// @Failure 	 403  {string} string
 // @Failure 	 500 {string} string
 // @Router       /get_comments_count [get]
 func (a *GatewayApp) GetCommentsCount(w http.ResponseWriter, r *http.Request) {
-	logger := logger.Logger.With("path", r.URL.Path, "method", r.Method)
-
-	query := r.URL.Query()
-	postIdStr := query.Get("post_id")
-
-	postId, err := strconv.Atoi(postIdStr)
-	if err != nil {
-		logger.Error("postId is empty")
-		writeRes(w, http.StatusBadRequest, "postId is empty")
-	}
-
-	req := models.PostID{PostID: postId}
-
-	res, err := a.GRPCClients.StatisticServiceClient.GetCommentsCount(r.Context(), req.ToStatisticProto())
-	if err != nil {
-		logger.Error("error grpc request GetCommentsCount", status.Convert(err).Message())
-		writeRes(w, http.StatusInternalServerError, status.Convert(err).Message())
-		return
-	}
-
-	writeRes(w, http.StatusOK, models.FromProtoCountResponse(res))
+	a.writeStats(w, r, "comments", countStat)
 }
 
 // GetCommentsDynamic godoc
 // @Summary      Получить динамику комментариев по посту
 // @Description  Получить динамику комментариев по посту
 // @Tags         Statistic
+// @Security BearerAuth
 // @Produce      json
 // @Param 		 post_id query int true "ID поста"
 // @Success      200  {object} models.DynamicListResponse
 // @Failure 	 400 {string} string
 // @Failure 	 401  {string} string
+// @Failure 	 403  {string} string
 // @Failure 	 500 {string} string
 // @Router       /get_comments_dynamic [get]
 func (a *GatewayApp) GetCommentsDynamic(w http.ResponseWriter, r *http.Request) {
-	logger := logger.Logger.With("path", r.URL.Path, "method", r.Method)
-
-	query := r.URL.Query()
-	postIdStr := query.Get("post_id")
-
-	postId, err := strconv.Atoi(postIdStr)
-	if err != nil {
-		logger.Error("postId is empty")
-		writeRes(w, http.StatusBadRequest, "postId is empty")
-	}
-
-	req := models.PostID{PostID: postId}
-
-	res, err := a.GRPCClients.StatisticServiceClient.GetCommentsDynamic(r.Context(), req.ToStatisticProto())
-	if err != nil {
-		logger.Error("error grpc request GetCommentsDynamic", status.Convert(err).Message())
-		writeRes(w, http.StatusInternalServerError, status.Convert(err).Message())
-		return
-	}
-
-	writeRes(w, http.StatusOK, models.FromProtoDynamuicListResponse(res))
+	a.writeStats(w, r, "comments", dynamicStat)
 }
 
 // GetLikesDynamic godoc
 // @Summary      Получить динамику лайков по посту
 // @Description  Получить динамику лайков по посту
 // @Tags         Statistic
+// @Security BearerAuth
 // @Produce      json
 // @Param 		 post_id query int true "ID поста"
 // @Success      200  {object} models.DynamicListResponse
 // @Failure 	 400 {string} string
 // @Failure 	 401  {string} string
+// @Failure 	 403  {string} string
 // @Failure 	 500 {string} string
 // @Router       /get_likes_dynamic [get]
 func (a *GatewayApp) GetLikesDynamic(w http.ResponseWriter, r *http.Request) {
-	logger := logger.Logger.With("path", r.URL.Path, "method", r.Method)
-
-	query := r.URL.Query()
-	postIdStr := query.Get("post_id")
-
-	postId, err := strconv.Atoi(postIdStr)
-	if err != nil {
-		logger.Error("postId is empty")
-		writeRes(w, http.StatusBadRequest, "postId is empty")
-	}
-
-	req := models.PostID{PostID: postId}
-
-	res, err := a.GRPCClients.StatisticServiceClient.GetLikesDynamic(r.Context(), req.ToStatisticProto())
-	if err != nil {
-		logger.Error("error grpc request GetLikesDynamic", status.Convert(err).Message())
-		writeRes(w, http.StatusInternalServerError, status.Convert(err).Message())
-		return
-	}
-
-	writeRes(w, http.StatusOK, models.FromProtoDynamuicListResponse(res))
+	a.writeStats(w, r, "likes", dynamicStat)
 }
 
 // GetViewsDynamic godoc
 // @Summary      Получить динамику просмотров по посту
 // @Description  Получить динамику просмотров по посту
 // @Tags         Statistic
+// @Security BearerAuth
 // @Produce      json
 // @Param 		 post_id query int true "ID поста"
 // @Success      200  {object} models.DynamicListResponse
 // @Failure 	 400 {string} string
 // @Failure 	 401  {string} string
+// @Failure 	 403  {string} string
 // @Failure 	 500 {string} string
 // @Router       /get_views_dynamic [get]
 func (a *GatewayApp) GetViewsDynamic(w http.ResponseWriter, r *http.Request) {
-	logger := logger.Logger.With("path", r.URL.Path, "method", r.Method)
-
-	query := r.URL.Query()
-	postIdStr := query.Get("post_id")
+	a.writeStats(w, r, "views", dynamicStat)
+}
 
-	postId, err := strconv.Atoi(postIdStr)
-	if err != nil {
-		logger.Error("postId is empty")
-		writeRes(w, http.StatusBadRequest, "postId is empty")
-	}
+// GetUniqueViewersCount godoc
+// @Summary      Получить количество уникальных зрителей поста
+// @Description  Получить количество уникальных зрителей поста
+// @Tags         Statistic
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 post_id query int true "ID поста"
+// @Success      200  {object} models.CountResponse
+// @Failure 	 400 {string} string
+// @Failure 	 401  {string} string
+// @Failure 	 403  {string} string
+// @Failure 	 500 {string} string
+// @Router       /get_unique_viewers_count [get]
+func (a *GatewayApp) GetUniqueViewersCount(w http.ResponseWriter, r *http.Request) {
+	a.writeStats(w, r, "unique_viewers", countStat)
+}
 
-	req := models.PostID{PostID: postId}
+// GetUniqueLikersCount godoc
+// @Summary      Получить количество уникальных пользователей, лайкнувших пост
+// @Description  Получить количество уникальных пользователей, лайкнувших пост
+// @Tags         Statistic
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 post_id query int true "ID поста"
+// @Success      200  {object} models.CountResponse
+// @Failure 	 400 {string} string
+// @Failure 	 401  {string} string
+// @Failure 	 403  {string} string
+// @Failure 	 500 {string} string
+// @Router       /get_unique_likers_count [get]
+func (a *GatewayApp) GetUniqueLikersCount(w http.ResponseWriter, r *http.Request) {
+	a.writeStats(w, r, "unique_likers", countStat)
+}
 
-	res, err := a.GRPCClients.StatisticServiceClient.GetViewsDynamic(r.Context(), req.ToStatisticProto())
-	if err != nil {
-		logger.Error("error grpc request GetViewsDynamic", status.Convert(err).Message())
-		writeRes(w, http.StatusInternalServerError, status.Convert(err).Message())
-		return
-	}
+// GetUniqueViewersDynamic godoc
+// @Summary      Получить динамику уникальных зрителей поста
+// @Description  Получить динамику уникальных зрителей поста
+// @Tags         Statistic
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 post_id query int true "ID поста"
+// @Success      200  {object} models.DynamicListResponse
+// @Failure 	 400 {string} string
+// @Failure 	 401  {string} string
+// @Failure 	 403  {string} string
+// @Failure 	 500 {string} string
+// @Router       /get_unique_viewers_dynamic [get]
+func (a *GatewayApp) GetUniqueViewersDynamic(w http.ResponseWriter, r *http.Request) {
+	a.writeStats(w, r, "unique_viewers", dynamicStat)
+}
 
-	writeRes(w, http.StatusOK, models.FromProtoDynamuicListResponse(res))
+// GetUniqueLikersDynamic godoc
+// @Summary      Получить динамику уникальных пользователей, лайкнувших пост
+// @Description  Получить динамику уникальных пользователей, лайкнувших пост
+// @Tags         Statistic
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 post_id query int true "ID поста"
+// @Success      200  {object} models.DynamicListResponse
+// @Failure 	 400 {string} string
+// @Failure 	 401  {string} string
+// @Failure 	 403  {string} string
+// @Failure 	 500 {string} string
+// @Router       /get_unique_likers_dynamic [get]
+func (a *GatewayApp) GetUniqueLikersDynamic(w http.ResponseWriter, r *http.Request) {
+	a.writeStats(w, r, "unique_likers", dynamicStat)
 }
 
 // GetTopTenPosts godoc
 // @Summary      Получить топ 10 постов по параметру
 // @Description  Получить топ 10 постов по параметру
 // @Tags         Statistic
+// @Security BearerAuth
 // @Produce      json
 // @Param 		 top_parameter query string true "Параметер топа"
 // @Success      200  {object} models.TopTenResponse
 // @Failure 	 400 {string} string
 // @Failure 	 401  {string} string
+// @Failure 	 403  {string} string
 // @Failure 	 500 {string} string
 // @Router       /get_top_ten_posts [get]
 func (a *GatewayApp) GetTopTenPosts(w http.ResponseWriter, r *http.Request) {
-	logger := logger.Logger.With("path", r.URL.Path, "method", r.Method)
+	logger := logger.FromContext(r.Context()).With("path", r.URL.Path, "method", r.Method)
 
 	query := r.URL.Query()
 	par := query.Get("top_parameter")
 
 	req := models.TopParameter{Parameter: par}
 
-	res, err := a.GRPCClients.StatisticServiceClient.GetTopTenPosts(r.Context(), req.ToStatisticProto())
+	res, err := a.GRPCClients.StatisticServiceClient.GetTopTenPosts(withUserID(r), req.ToStatisticProto())
 	if err != nil {
-		logger.Error("error grpc request GetTopTenPosts", status.Convert(err).Message())
-		writeRes(w, http.StatusInternalServerError, status.Convert(err).Message())
+		logger.Error("error grpc request GetTopTenPosts", "error", status.Convert(err).Message())
+		writeRes(w, httpStatus(err), status.Convert(err).Message())
 		return
 	}
 
@@ -773,27 +765,201 @@ func (a *GatewayApp) GetTopTenPosts(w http.ResponseWriter, r *http.Request) {
 // @Summary      Получить топ 10 пользователей по параметру
 // @Description  Получить топ 10 пользователей по параметру
 // @Tags         Statistic
+// @Security BearerAuth
 // @Produce      json
 // @Param 		 top_parameter query string true "Параметер топа"
 // @Success      200  {object} models.TopTenResponse
 // @Failure 	 400 {string} string
 // @Failure 	 401  {string} string
+// @Failure 	 403  {string} string
 // @Failure 	 500 {string} string
 // @Router       /get_top_ten_users [get]
 func (a *GatewayApp) GetTopTenUsers(w http.ResponseWriter, r *http.Request) {
-	logger := logger.Logger.With("path", r.URL.Path, "method", r.Method)
+	logger := logger.FromContext(r.Context()).With("path", r.URL.Path, "method", r.Method)
 
 	query := r.URL.Query()
 	par := query.Get("top_parameter")
 
 	req := models.TopParameter{Parameter: par}
 
-	res, err := a.GRPCClients.StatisticServiceClient.GetTopTenUsers(r.Context(), req.ToStatisticProto())
+	res, err := a.GRPCClients.StatisticServiceClient.GetTopTenUsers(withUserID(r), req.ToStatisticProto())
 	if err != nil {
-		logger.Error("error grpc request GetTopTenUsers", status.Convert(err).Message())
-		writeRes(w, http.StatusInternalServerError, status.Convert(err).Message())
+		logger.Error("error grpc request GetTopTenUsers", "error", status.Convert(err).Message())
+		writeRes(w, httpStatus(err), status.Convert(err).Message())
 		return
 	}
 
 	writeRes(w, http.StatusOK, models.FromProtoTopTenUsersResponse(res))
 }
+
+// GetAuthorStats godoc
+// @Summary      Получить статистику автора
+// @Description  Получить суммарную статистику и динамику по всем постам автора, топ постов и вовлеченность
+// @Tags         Statistic
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 user_id query int true "ID автора"
+// @Success      200  {object} models.AuthorStatsResponse
+// @Failure 	 400 {string} string
+// @Failure 	 401  {string} string
+// @Failure 	 403  {string} string
+// @Failure 	 500 {string} string
+// @Router       /get_author_stats [get]
+func (a *GatewayApp) GetAuthorStats(w http.ResponseWriter, r *http.Request) {
+	logger := logger.FromContext(r.Context()).With("path", r.URL.Path, "method", r.Method)
+
+	query := r.URL.Query()
+	userIdStr := query.Get("user_id")
+
+	userId, err := strconv.Atoi(userIdStr)
+	if err != nil {
+		logger.Error("userId is empty")
+		writeRes(w, http.StatusBadRequest, "userId is empty")
+		return
+	}
+
+	req := models.UserID{UserID: userId}
+
+	res, err := a.GRPCClients.StatisticServiceClient.GetAuthorStats(withUserID(r), req.ToStatisticProto())
+	if err != nil {
+		logger.Error("error grpc request GetAuthorStats", "error", status.Convert(err).Message())
+		writeRes(w, httpStatus(err), status.Convert(err).Message())
+		return
+	}
+
+	writeRes(w, http.StatusOK, models.FromProtoAuthorStatsResponse(res))
+}
+
+// GetNotifications godoc
+// @Summary      Получить уведомления
+// @Description  Получить пагинированный список уведомлений о лайках и комментариях к постам пользователя
+// @Tags         Notification
+// @Security BearerAuth
+// @Produce      json
+// @Param        page query int true "Номер страницы"
+// @Param        page_size query int true "Количество элементов на странице"
+// @Success      200  {object} models.GetNotificationsResponse
+// @Failure 	 400 {string} string
+// @Failure 	 401  {string} string
+// @Failure 	 500 {string} string
+// @Router       /get_notifications [get]
+func (a *GatewayApp) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	logger := logger.FromContext(r.Context()).With("path", r.URL.Path, "method", r.Method)
+
+	query := r.URL.Query()
+	pageStr := query.Get("page")
+	pageSizeStr := query.Get("page_size")
+
+	if pageStr == "" || pageSizeStr == "" {
+		writeRes(w, http.StatusBadRequest, "page or page size is empty")
+		return
+	}
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(pageSizeStr)
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	userID := r.Header.Get("UserID")
+	if userID == "" {
+		logger.Error("user_id is empty")
+		writeRes(w, http.StatusBadRequest, "user_id is empty")
+		return
+	}
+
+	ctx := metadata.AppendToOutgoingContext(r.Context(), "user_id", userID)
+	res, err := a.GRPCClients.NotificationsServiceClient.GetNotifications(ctx, &pb.PaginatedListRequest{Page: int32(page), PageSize: int32(pageSize)})
+	if err != nil {
+		logger.Error("error grpc request GetNotifications", "error", status.Convert(err).Message())
+		writeRes(w, http.StatusInternalServerError, status.Convert(err).Message())
+		return
+	}
+
+	writeRes(w, http.StatusOK, models.FromProtoListNotificationsResponse(res))
+}
+
+// MarkNotificationsRead godoc
+// @Summary      Прочитать уведомления
+// @Description  Отметить уведомления прочитанными, при all = true отмечаются все уведомления
+// @Tags         Notification
+// @Security BearerAuth
+// @Accept		 json
+// @Produce      json
+// @Param 		 notifications body models.MarkNotificationsReadRequest true "ID уведомлений"
+// @Success      200  {string} string
+// @Failure 	 400 {string} string
+// @Failure 	 401  {string} string
+// @Failure 	 500 {string} string
+// @Router       /mark_notifications_read [post]
+func (a *GatewayApp) MarkNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	logger := logger.FromContext(r.Context()).With("path", r.URL.Path, "method", r.Method)
+
+	d, err := io.ReadAll(r.Body)
+	if err != nil {
+		logger.Error("read body error", "error", err.Error())
+		writeRes(w, http.StatusBadRequest, nil)
+		return
+	}
+
+	var req models.MarkNotificationsReadRequest
+	err = json.Unmarshal(d, &req)
+	if err != nil {
+		logger.Error("unmarshal error", "error", err.Error())
+		writeRes(w, http.StatusBadRequest, nil)
+		return
+	}
+
+	userID := r.Header.Get("UserID")
+	if userID == "" {
+		logger.Error("user_id is empty")
+		writeRes(w, http.StatusBadRequest, "user_id is empty")
+		return
+	}
+
+	ctx := metadata.AppendToOutgoingContext(r.Context(), "user_id", userID)
+	_, err = a.GRPCClients.NotificationsServiceClient.MarkNotificationsRead(ctx, req.ToNotificationsProto())
+	if err != nil {
+		logger.Error("error grpc request MarkNotificationsRead", "error", status.Convert(err).Message())
+		writeRes(w, http.StatusInternalServerError, status.Convert(err).Message())
+		return
+	}
+
+	writeRes(w, http.StatusOK, "Notifications are read")
+}
+
+// GetUnreadNotificationsCount godoc
+// @Summary      Получить количество непрочитанных уведомлений
+// @Description  Получить количество непрочитанных уведомлений
+// @Tags         Notification
+// @Security BearerAuth
+// @Produce      json
+// @Success      200  {object} models.CountResponse
+// @Failure 	 400 {string} string
+// @Failure 	 401  {string} string
+// @Failure 	 500 {string} string
+// @Router       /get_unread_notifications_count [get]
+func (a *GatewayApp) GetUnreadNotificationsCount(w http.ResponseWriter, r *http.Request) {
+	logger := logger.FromContext(r.Context()).With("path", r.URL.Path, "method", r.Method)
+
+	userID := r.Header.Get("UserID")
+	if userID == "" {
+		logger.Error("user_id is empty")
+		writeRes(w, http.StatusBadRequest, "user_id is empty")
+		return
+	}
+
+	ctx := metadata.AppendToOutgoingContext(r.Context(), "user_id", userID)
+	res, err := a.GRPCClients.NotificationsServiceClient.GetUnreadNotificationsCount(ctx, &emptypb.Empty{})
+	if err != nil {
+		logger.Error("error grpc request GetUnreadNotificationsCount", "error", status.Convert(err).Message())
+		writeRes(w, http.StatusInternalServerError, status.Convert(err).Message())
+		return
+	}
+
+	writeRes(w, http.StatusOK, models.FromProtoCountResponse(res))
+}
@@ -0,0 +1,190 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/models"
+	pb "github.com/grigorovskiiy/soa-hse/protos"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+const (
+	feedAuthor   = "author"
+	feedLikes    = "likes"
+	feedComments = "comments"
+	feedViews    = "views"
+)
+
+// GetFeed godoc
+// @Summary      Лента постов
+// @Description  Получить страницу постов вместе с автором, счётчиками лайков, комментариев и просмотров и признаком лайка текущего пользователя.
+// @Description  Если часть данных не удалось получить, пост возвращается без неё, её название попадает в missing, а partial = true.
+// @Tags         Feed
+// @Security BearerAuth
+// @Produce      json
+// @Param        page query int false "Номер страницы"
+// @Param        page_size query int false "Количество элементов на странице"
+// @Success      200  {object} models.FeedResponse
+// @Failure 	 401  {string} string
+// @Failure 	 500 {string} string
+// @Router       /api/v2/feed [get]
+func (a *GatewayApp) GetFeed(w http.ResponseWriter, r *http.Request) {
+	logger := logger.FromContext(r.Context()).With("path", r.URL.Path, "method", r.Method)
+	ctx := withUserID(r)
+
+	page, pageSize := a.parseFeedPage(r)
+	postsCtx, cancel := context.WithTimeout(ctx, a.cfg.FeedCallTimeout)
+	posts, err := a.GRPCClients.PostsServiceClient.GetPostList(postsCtx, &pb.PaginatedListRequest{Page: page, PageSize: pageSize})
+	cancel()
+	if err != nil {
+		logger.Error("grpc request GetPostList error", "error", status.Convert(err).Message())
+		writeRes(w, httpStatus(err), status.Convert(err).Message())
+		return
+	}
+
+	feed := make([]*models.FeedPost, len(posts.Posts))
+	for i, post := range posts.Posts {
+		feed[i] = &models.FeedPost{GetPostResponse: *models.FromProtoPostResponse(post)}
+	}
+
+	var mu sync.Mutex
+	missing := func(post *models.FeedPost, part string, err error) {
+		logger.Warn("feed part is missing", "post_id", post.PostID, "part", part, "error", status.Convert(err).Message())
+		mu.Lock()
+		post.Missing = append(post.Missing, part)
+		mu.Unlock()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		a.fillFeedAuthors(ctx, feed, missing)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		a.fillFeedCounters(ctx, feed, missing)
+	}()
+	wg.Wait()
+
+	res := models.FeedResponse{Posts: feed}
+	for _, post := range feed {
+		if len(post.Missing) > 0 {
+			res.Partial = true
+			break
+		}
+	}
+	if res.Partial {
+		logger.Warn("feed is partial", "posts", len(feed))
+	}
+
+	writeRes(w, http.StatusOK, res)
+}
+
+func (a *GatewayApp) parseFeedPage(r *http.Request) (int32, int32) {
+	query := r.URL.Query()
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(query.Get("page_size"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+	if pageSize > a.cfg.FeedMaxPageSize {
+		pageSize = a.cfg.FeedMaxPageSize
+	}
+
+	return int32(page), int32(pageSize)
+}
+
+// fillFeedAuthors loads all authors of the page with one BatchGetUsers call.
+func (a *GatewayApp) fillFeedAuthors(ctx context.Context, feed []*models.FeedPost, missing func(*models.FeedPost, string, error)) {
+	seen := make(map[int32]bool)
+	var userIDs []int32
+	for _, post := range feed {
+		if id := int32(post.UserID); !seen[id] {
+			seen[id] = true
+			userIDs = append(userIDs, id)
+		}
+	}
+	if len(userIDs) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, a.cfg.FeedCallTimeout)
+	defer cancel()
+
+	users, err := a.GRPCClients.UsersServiceClient.BatchGetUsers(ctx, &pb.BatchGetUsersRequest{UserIds: userIDs})
+	if err != nil {
+		for _, post := range feed {
+			missing(post, feedAuthor, err)
+		}
+		return
+	}
+
+	authors := make(map[int]*models.FeedAuthor, len(users.Users))
+	for _, user := range users.Users {
+		authors[int(user.UserId)] = models.FromProtoFeedAuthor(user)
+	}
+	for _, post := range feed {
+		author, ok := authors[post.UserID]
+		if !ok {
+			missing(post, feedAuthor, errors.New("author is not found"))
+			continue
+		}
+		post.Author = author
+	}
+}
+
+// fillFeedCounters loads likes, comments and views of all posts of the page with one
+// BatchGetPostStats call.
+func (a *GatewayApp) fillFeedCounters(ctx context.Context, feed []*models.FeedPost, missing func(*models.FeedPost, string, error)) {
+	if len(feed) == 0 {
+		return
+	}
+
+	postIDs := make([]int32, len(feed))
+	for i, post := range feed {
+		postIDs[i] = int32(post.PostID)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, a.cfg.FeedCallTimeout)
+	defer cancel()
+
+	res, err := a.GRPCClients.StatisticServiceClient.BatchGetPostStats(ctx, &pb.BatchPostStatsRequest{PostIds: postIDs})
+	if err != nil {
+		for _, post := range feed {
+			for _, part := range []string{feedLikes, feedComments, feedViews} {
+				missing(post, part, err)
+			}
+		}
+		return
+	}
+
+	stats := make(map[int]*pb.PostStats, len(res.Stats))
+	for _, s := range res.Stats {
+		stats[int(s.PostId)] = s
+	}
+	for _, post := range feed {
+		s, ok := stats[post.PostID]
+		if !ok {
+			err := errors.New("post stats are not found")
+			for _, part := range []string{feedLikes, feedComments, feedViews} {
+				missing(post, part, err)
+			}
+			continue
+		}
+
+		likes, comments, views := int(s.LikesCount), int(s.CommentsCount), int(s.ViewsCount)
+		post.LikesCount, post.CommentsCount, post.ViewsCount = &likes, &comments, &views
+	}
+}
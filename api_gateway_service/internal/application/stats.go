@@ -0,0 +1,138 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/models"
+	pb "github.com/grigorovskiiy/soa-hse/protos"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"strconv"
+)
+
+const (
+	countStat   = "count"
+	dynamicStat = "dynamic"
+)
+
+// statsMetric holds the statistic_service methods that return one metric of a post.
+type statsMetric struct {
+	count   func(context.Context, *pb.PostID, ...grpc.CallOption) (*pb.CountResponse, error)
+	dynamic func(context.Context, *pb.PostID, ...grpc.CallOption) (*pb.DynamicListResponse, error)
+}
+
+func newStatsMetrics(client pb.StatisticServiceClient) map[string]statsMetric {
+	return map[string]statsMetric{
+		"views":          {count: client.GetViewsCount, dynamic: client.GetViewsDynamic},
+		"likes":          {count: client.GetLikesCount, dynamic: client.GetLikesDynamic},
+		"comments":       {count: client.GetCommentsCount, dynamic: client.GetCommentsDynamic},
+		"unique_viewers": {count: client.GetUniqueViewersCount, dynamic: client.GetUniqueViewersDynamic},
+		"unique_likers":  {count: client.GetUniqueLikersCount, dynamic: client.GetUniqueLikersDynamic},
+	}
+}
+
+// GetStats serves /stats/{metric}/{kind}, the kinds return different responses and are
+// documented by GetStatsCount and GetStatsDynamic.
+func (a *GatewayApp) GetStats(w http.ResponseWriter, r *http.Request) {
+	switch kind := r.PathValue("kind"); kind {
+	case countStat:
+		a.GetStatsCount(w, r)
+	case dynamicStat:
+		a.GetStatsDynamic(w, r)
+	default:
+		a.writeStats(w, r, r.PathValue("metric"), kind)
+	}
+}
+
+// GetStatsCount godoc
+// @Summary      Получить количество по посту
+// @Description  Получить количество для метрики views, likes, comments, unique_viewers или unique_likers
+// @Tags         Statistic
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 metric path string true "Метрика"
+// @Param 		 post_id query int true "ID поста"
+// @Success      200  {object} models.CountResponse
+// @Failure 	 400 {string} string
+// @Failure 	 401  {string} string
+// @Failure 	 403  {string} string
+// @Failure 	 404  {string} string
+// @Failure 	 500 {string} string
+// @Router       /stats/{metric}/count [get]
+func (a *GatewayApp) GetStatsCount(w http.ResponseWriter, r *http.Request) {
+	a.writeStats(w, r, r.PathValue("metric"), countStat)
+}
+
+// GetStatsDynamic godoc
+// @Summary      Получить динамику по посту
+// @Description  Получить динамику по дням для метрики views, likes, comments, unique_viewers или unique_likers
+// @Tags         Statistic
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 metric path string true "Метрика"
+// @Param 		 post_id query int true "ID поста"
+// @Success      200  {object} models.DynamicListResponse
+// @Failure 	 400 {string} string
+// @Failure 	 401  {string} string
+// @Failure 	 403  {string} string
+// @Failure 	 404  {string} string
+// @Failure 	 500 {string} string
+// @Router       /stats/{metric}/dynamic [get]
+func (a *GatewayApp) GetStatsDynamic(w http.ResponseWriter, r *http.Request) {
+	a.writeStats(w, r, r.PathValue("metric"), dynamicStat)
+}
+
+func (a *GatewayApp) writeStats(w http.ResponseWriter, r *http.Request, metricName string, kind string) {
+	logger := logger.FromContext(r.Context()).With("path", r.URL.Path, "method", r.Method, "metric", metricName, "kind", kind)
+
+	metric, ok := a.statsMetrics[metricName]
+	if !ok || (kind != countStat && kind != dynamicStat) {
+		logger.Error("unknown statistic")
+		writeRes(w, http.StatusNotFound, "unknown statistic")
+		return
+	}
+
+	postID, err := parsePostID(r)
+	if err != nil {
+		logger.Error("parse post_id error", "error", err.Error())
+		writeRes(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var res any
+	if kind == countStat {
+		var count *pb.CountResponse
+		if count, err = metric.count(withUserID(r), postID); err == nil {
+			res = models.FromProtoCountResponse(count)
+		}
+	} else {
+		var dynamic *pb.DynamicListResponse
+		if dynamic, err = metric.dynamic(withUserID(r), postID); err == nil {
+			res = models.FromProtoDynamuicListResponse(dynamic)
+		}
+	}
+	if err != nil {
+		logger.Error("error grpc statistic request", "error", status.Convert(err).Message())
+		writeRes(w, httpStatus(err), status.Convert(err).Message())
+		return
+	}
+
+	writeRes(w, http.StatusOK, res)
+}
+
+func parsePostID(r *http.Request) (*pb.PostID, error) {
+	postIDStr := r.URL.Query().Get("post_id")
+	if postIDStr == "" {
+		return nil, errors.New("post_id is empty")
+	}
+
+	postID, err := strconv.Atoi(postIDStr)
+	if err != nil || postID <= 0 {
+		return nil, errors.New("post_id is invalid")
+	}
+
+	req := models.PostID{PostID: postID}
+	return req.ToStatisticProto(), nil
+}
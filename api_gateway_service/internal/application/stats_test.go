@@ -0,0 +1,230 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/models"
+	pb "github.com/grigorovskiiy/soa-hse/protos"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// statsCounts gives every statistic_service method its own answer, so a handler calling the
+// wrong method is caught by the value it returns.
+var statsCounts = map[string]int32{
+	"GetViewsCount":           1,
+	"GetLikesCount":           2,
+	"GetCommentsCount":        3,
+	"GetUniqueViewersCount":   4,
+	"GetUniqueLikersCount":    5,
+	"GetViewsDynamic":         6,
+	"GetLikesDynamic":         7,
+	"GetCommentsDynamic":      8,
+	"GetUniqueViewersDynamic": 9,
+	"GetUniqueLikersDynamic":  10,
+}
+
+var statsDate = time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+type fakeStatisticServer struct {
+	pb.UnimplementedStatisticServiceServer
+}
+
+func (fakeStatisticServer) count(method string, in *pb.PostID) (*pb.CountResponse, error) {
+	if in.PostId != 42 {
+		return nil, status.Errorf(codes.NotFound, "unexpected post %d", in.PostId)
+	}
+
+	return &pb.CountResponse{Count: statsCounts[method]}, nil
+}
+
+func (fakeStatisticServer) dynamic(method string, in *pb.PostID) (*pb.DynamicListResponse, error) {
+	if in.PostId != 42 {
+		return nil, status.Errorf(codes.NotFound, "unexpected post %d", in.PostId)
+	}
+
+	return &pb.DynamicListResponse{Dynamic: []*pb.DynamicResponse{{
+		Count: &pb.CountResponse{Count: statsCounts[method]},
+		Data:  timestamppb.New(statsDate),
+	}}}, nil
+}
+
+func (s fakeStatisticServer) GetViewsCount(_ context.Context, in *pb.PostID) (*pb.CountResponse, error) {
+	return s.count("GetViewsCount", in)
+}
+
+func (s fakeStatisticServer) GetLikesCount(_ context.Context, in *pb.PostID) (*pb.CountResponse, error) {
+	return s.count("GetLikesCount", in)
+}
+
+func (s fakeStatisticServer) GetCommentsCount(_ context.Context, in *pb.PostID) (*pb.CountResponse, error) {
+	return s.count("GetCommentsCount", in)
+}
+
+func (s fakeStatisticServer) GetUniqueViewersCount(_ context.Context, in *pb.PostID) (*pb.CountResponse, error) {
+	return s.count("GetUniqueViewersCount", in)
+}
+
+func (s fakeStatisticServer) GetUniqueLikersCount(_ context.Context, in *pb.PostID) (*pb.CountResponse, error) {
+	return s.count("GetUniqueLikersCount", in)
+}
+
+func (s fakeStatisticServer) GetViewsDynamic(_ context.Context, in *pb.PostID) (*pb.DynamicListResponse, error) {
+	return s.dynamic("GetViewsDynamic", in)
+}
+
+func (s fakeStatisticServer) GetLikesDynamic(_ context.Context, in *pb.PostID) (*pb.DynamicListResponse, error) {
+	return s.dynamic("GetLikesDynamic", in)
+}
+
+func (s fakeStatisticServer) GetCommentsDynamic(_ context.Context, in *pb.PostID) (*pb.DynamicListResponse, error) {
+	return s.dynamic("GetCommentsDynamic", in)
+}
+
+func (s fakeStatisticServer) GetUniqueViewersDynamic(_ context.Context, in *pb.PostID) (*pb.DynamicListResponse, error) {
+	return s.dynamic("GetUniqueViewersDynamic", in)
+}
+
+func (s fakeStatisticServer) GetUniqueLikersDynamic(_ context.Context, in *pb.PostID) (*pb.DynamicListResponse, error) {
+	return s.dynamic("GetUniqueLikersDynamic", in)
+}
+
+// newStatsServer serves GetStats like the gateway does, with statistic_service replaced by
+// fakeStatisticServer behind an in-memory gRPC connection.
+func newStatsServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterStatisticServiceServer(srv, fakeStatisticServer{})
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial statistic service: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	a := &GatewayApp{statsMetrics: newStatsMetrics(pb.NewStatisticServiceClient(conn))}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /stats/{metric}/{kind}", a.GetStats)
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func getStats(t *testing.T, ts *httptest.Server, path string, res any) int {
+	t.Helper()
+
+	resp, err := http.Get(ts.URL + path)
+	if err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK && res != nil {
+		if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
+			t.Fatalf("decode %s: %v", path, err)
+		}
+	}
+
+	return resp.StatusCode
+}
+
+func TestGetStatsReturnsEachMetric(t *testing.T) {
+	ts := newStatsServer(t)
+
+	tests := []struct {
+		metric, kind, method string
+	}{
+		{"views", countStat, "GetViewsCount"},
+		{"likes", countStat, "GetLikesCount"},
+		{"comments", countStat, "GetCommentsCount"},
+		{"unique_viewers", countStat, "GetUniqueViewersCount"},
+		{"unique_likers", countStat, "GetUniqueLikersCount"},
+		{"views", dynamicStat, "GetViewsDynamic"},
+		{"likes", dynamicStat, "GetLikesDynamic"},
+		{"comments", dynamicStat, "GetCommentsDynamic"},
+		{"unique_viewers", dynamicStat, "GetUniqueViewersDynamic"},
+		{"unique_likers", dynamicStat, "GetUniqueLikersDynamic"},
+	}
+	if metrics := newStatsMetrics(pb.NewStatisticServiceClient(nil)); len(tests) != len(metrics)*2 {
+		t.Fatalf("%d cases for %d metrics, every metric needs a count and a dynamic case",
+			len(tests), len(metrics))
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.metric+"/"+tt.kind, func(t *testing.T) {
+			path := "/stats/" + tt.metric + "/" + tt.kind + "?post_id=42"
+			want := statsCounts[tt.method]
+
+			if tt.kind == countStat {
+				var res models.CountResponse
+				if code := getStats(t, ts, path, &res); code != http.StatusOK {
+					t.Fatalf("status %d, want %d", code, http.StatusOK)
+				}
+				if res.Count != want {
+					t.Errorf("count %d, want %d of %s", res.Count, want, tt.method)
+				}
+				return
+			}
+
+			var res models.DynamicListResponse
+			if code := getStats(t, ts, path, &res); code != http.StatusOK {
+				t.Fatalf("status %d, want %d", code, http.StatusOK)
+			}
+			if len(res.Dynamic) != 1 {
+				t.Fatalf("%d days, want 1", len(res.Dynamic))
+			}
+			if int32(res.Dynamic[0].Count) != want {
+				t.Errorf("count %d, want %d of %s", res.Dynamic[0].Count, want, tt.method)
+			}
+			if !res.Dynamic[0].Date.Equal(statsDate) {
+				t.Errorf("date %v, want %v", res.Dynamic[0].Date, statsDate)
+			}
+		})
+	}
+}
+
+func TestGetStatsRejectsBadRequests(t *testing.T) {
+	ts := newStatsServer(t)
+
+	tests := []struct {
+		name, path string
+		code       int
+	}{
+		{"unknown metric", "/stats/shares/count?post_id=42", http.StatusNotFound},
+		{"unknown kind", "/stats/views/total?post_id=42", http.StatusNotFound},
+		{"missing post_id", "/stats/views/count", http.StatusBadRequest},
+		{"invalid post_id", "/stats/views/count?post_id=abc", http.StatusBadRequest},
+		{"non-positive post_id", "/stats/comments/dynamic?post_id=0", http.StatusBadRequest},
+		{"unknown post", "/stats/comments/dynamic?post_id=7", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := getStats(t, ts, tt.path, nil); code != tt.code {
+				t.Errorf("status %d, want %d", code, tt.code)
+			}
+		})
+	}
+}
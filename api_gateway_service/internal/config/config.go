@@ -3,13 +3,24 @@ package config
 import (
 	"github.com/caarlos0/env/v8"
 	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/logger"
+	"path"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	PostsServiceConfig
 	StatisticServiceConfig
 	UsersServiceConfig
+	NotificationsServiceConfig
 	GatewayServiceConfig
+	FeedConfig
+	TimeoutConfig
+	TracingConfig
+	MetricsConfig
+	HealthConfig
+	AccessLogConfig
+	RateLimitConfig
 }
 
 type PostsServiceConfig struct {
@@ -23,8 +34,14 @@ type StatisticServiceConfig struct {
 }
 
 type UsersServiceConfig struct {
-	UsersServicePort string `env:"USERS_SERVICE_PORT" envDefault:":8081"`
-	UsersServiceHost string `env:"USERS_SERVICE_HOST" envDefault:"users-service"`
+	UsersServicePort     string `env:"USERS_SERVICE_PORT" envDefault:":8081"`
+	UsersServiceGRPCPort string `env:"USERS_SERVICE_GRPC_PORT" envDefault:":50054"`
+	UsersServiceHost     string `env:"USERS_SERVICE_HOST" envDefault:"users-service"`
+}
+
+type NotificationsServiceConfig struct {
+	NotificationsServicePort string `env:"NOTIFICATIONS_SERVICE_PORT" envDefault:":50053"`
+	NotificationsServiceHost string `env:"NOTIFICATIONS_SERVICE_HOST" envDefault:"notifications-service"`
 }
 
 type GatewayServiceConfig struct {
@@ -32,6 +49,86 @@ type GatewayServiceConfig struct {
 	GatewayServiceHost string `env:"GATEWAY_SERVICE_HOST" envDefault:"api-gateway-service"`
 }
 
+// FeedConfig limits the fan-out of the feed, every backend call gets FeedCallTimeout.
+type FeedConfig struct {
+	FeedCallTimeout time.Duration `env:"FEED_CALL_TIMEOUT" envDefault:"500ms"`
+	FeedMaxPageSize int           `env:"FEED_MAX_PAGE_SIZE" envDefault:"50"`
+}
+
+// TimeoutConfig bounds the handling of every request. RouteTimeouts overrides RequestTimeout
+// for paths starting with the key, e.g. ROUTE_TIMEOUTS=/api/v2/feed:2s,/get_author_stats:10s.
+type TimeoutConfig struct {
+	RequestTimeout time.Duration            `env:"REQUEST_TIMEOUT" envDefault:"5s"`
+	RouteTimeouts  map[string]time.Duration `env:"ROUTE_TIMEOUTS"`
+}
+
+// RouteTimeout returns the timeout of the longest RouteTimeouts prefix of path.
+func (c TimeoutConfig) RouteTimeout(path string) time.Duration {
+	timeout, matched := c.RequestTimeout, ""
+	for prefix, t := range c.RouteTimeouts {
+		if strings.HasPrefix(path, prefix) && len(prefix) > len(matched) {
+			timeout, matched = t, prefix
+		}
+	}
+
+	return timeout
+}
+
+// TracingConfig selects where spans are exported: "otlp" sends them to TracingOTLPEndpoint,
+// "stdout" prints them and "none" only propagates the trace context.
+type TracingConfig struct {
+	TracingExporter     string  `env:"TRACING_EXPORTER" envDefault:"otlp"`
+	TracingOTLPEndpoint string  `env:"TRACING_OTLP_ENDPOINT" envDefault:"jaeger:4317"`
+	TracingSampleRatio  float64 `env:"TRACING_SAMPLE_RATIO" envDefault:"1"`
+}
+
+// MetricsConfig is the address of the /metrics endpoint, it is served apart from the API.
+type MetricsConfig struct {
+	MetricsPort string `env:"METRICS_PORT" envDefault:":9090"`
+}
+
+// HealthConfig bounds the health checks of the downstream services made by /readyz.
+type HealthConfig struct {
+	HealthCheckTimeout time.Duration `env:"HEALTH_CHECK_TIMEOUT" envDefault:"2s"`
+}
+
+// AccessLogConfig configures the access log. AccessLogFormat is "json", "common" or "combined".
+// AccessLogSampling logs only a share of the requests to the paths matching the key as a
+// prefix or a path.Match pattern, e.g. /api/v2/posts/*/views.
+// Failed and slow requests are always logged.
+// TrustProxyHeaders takes the client IP from the last X-Forwarded-For address, the one appended
+// by the proxy in front of the gateway, instead of the connection.
+type AccessLogConfig struct {
+	AccessLogFormat      string             `env:"ACCESS_LOG_FORMAT" envDefault:"json"`
+	AccessLogSampling    map[string]float64 `env:"ACCESS_LOG_SAMPLING" envDefault:"/post_view:0.1,/api/v2/posts/*/views:0.1,/healthz:0"`
+	SlowRequestThreshold time.Duration      `env:"SLOW_REQUEST_THRESHOLD" envDefault:"1s"`
+	TrustProxyHeaders    bool               `env:"TRUST_PROXY_HEADERS" envDefault:"false"`
+}
+
+// SampleRate returns the share of the logged requests of the longest AccessLogSampling key
+// matching path, all requests are logged by default.
+func (c AccessLogConfig) SampleRate(p string) float64 {
+	rate, matched := 1.0, ""
+	for key, r := range c.AccessLogSampling {
+		ok, _ := path.Match(key, p)
+		if (ok || strings.HasPrefix(p, key)) && len(key) > len(matched) {
+			rate, matched = r, key
+		}
+	}
+
+	return rate
+}
+
+// RateLimitConfig limits the requests of every IP and, for requests with a valid token, of
+// every user as well. A policy is "<requests>/<period>", e.g. 5/1m, "0/1m" turns the limit off.
+// RateLimits overrides RateLimitDefault for the routes matching the key: a path.Match pattern
+// of the path, optionally preceded by the method, e.g. RATE_LIMITS=POST /api/v2/users:5/1m.
+type RateLimitConfig struct {
+	RateLimitEnabled bool              `env:"RATE_LIMIT_ENABLED" envDefault:"true"`
+	RateLimitDefault string            `env:"RATE_LIMIT_DEFAULT" envDefault:"600/1m"`
+	RateLimits       map[string]string `env:"RATE_LIMITS" envDefault:"/login:10/1m,/register:5/1m,POST /api/v2/users/login:10/1m,POST /api/v2/users:5/1m,/post_view:60/1m,/post_like:30/1m,POST /api/v2/posts/*/views:60/1m,POST /api/v2/posts/*/likes:30/1m"`
+}
+
 func NewConfig() (*Config, error) {
 	cfg := Config{}
 
@@ -0,0 +1,33 @@
+package errors
+
+type UnknownTracingExporterError struct {
+	Exporter string
+}
+
+func (err UnknownTracingExporterError) Error() string {
+	return "Unknown tracing exporter: " + err.Exporter
+}
+
+type NotServingError struct {
+	Status string
+}
+
+func (err NotServingError) Error() string {
+	return "Service is not serving: " + err.Status
+}
+
+type UnknownAccessLogFormatError struct {
+	Format string
+}
+
+func (err UnknownAccessLogFormatError) Error() string {
+	return "Unknown access log format: " + err.Format
+}
+
+type InvalidRateLimitPolicyError struct {
+	Policy string
+}
+
+func (err InvalidRateLimitPolicyError) Error() string {
+	return "Invalid rate limit policy: " + err.Policy
+}
@@ -6,19 +6,51 @@ import (
 	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/config"
 	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/logger"
 	pb "github.com/grigorovskiiy/soa-hse/protos"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
 )
 
 type GRPCClients struct {
-	PostsServiceClient     pb.PostsServiceClient
-	StatisticServiceClient pb.StatisticServiceClient
+	PostsServiceClient         pb.PostsServiceClient
+	StatisticServiceClient     pb.StatisticServiceClient
+	NotificationsServiceClient pb.NotificationsServiceClient
+	UsersServiceClient         pb.UsersServiceClient
+
+	// Health holds the grpc.health.v1 clients of the backend services keyed by service name.
+	Health map[string]healthpb.HealthClient
+}
+
+// requestMetadataInterceptor passes the ID of the request and the user checked by AuthMiddleware
+// on to the backend services in the metadata. The values are set rather than appended, so that
+// the backends get exactly one of each whatever the call already carried.
+func requestMetadataInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	if id := logger.RequestID(ctx); id != "" {
+		md.Set(logger.RequestIDKey, id)
+	}
+	if id := logger.UserID(ctx); id != "" {
+		md.Set("user_id", id)
+	}
+
+	return invoker(metadata.NewOutgoingContext(ctx, md), method, req, reply, cc, opts...)
 }
 
-func NewGRPCClients(lc fx.Lifecycle, cfg *config.Config) (*GRPCClients, error) {
+func NewGRPCClients(lc fx.Lifecycle, cfg *config.Config, tp trace.TracerProvider) (*GRPCClients, error) {
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler(otelgrpc.WithTracerProvider(tp))),
+		grpc.WithChainUnaryInterceptor(requestMetadataInterceptor),
+	}
+
 	postsConn, err := grpc.NewClient(fmt.Sprintf("%s%s", cfg.PostsServiceHost, cfg.PostsServicePort),
-		grpc.WithTransportCredentials(insecure.NewCredentials()))
+		opts...)
 
 	if err != nil {
 		logger.Logger.Error("error creating posts service grpc client", err.Error())
@@ -26,16 +58,40 @@ func NewGRPCClients(lc fx.Lifecycle, cfg *config.Config) (*GRPCClients, error) {
 	}
 
 	statisticConn, err := grpc.NewClient(fmt.Sprintf("%s%s", cfg.StatisticServiceHost, cfg.StatisticServicePort),
-		grpc.WithTransportCredentials(insecure.NewCredentials()))
+		opts...)
 
 	if err != nil {
 		logger.Logger.Error("error creating statistic service grpc client", err.Error())
 		return nil, err
 	}
 
+	notificationsConn, err := grpc.NewClient(fmt.Sprintf("%s%s", cfg.NotificationsServiceHost, cfg.NotificationsServicePort),
+		opts...)
+
+	if err != nil {
+		logger.Logger.Error("error creating notifications service grpc client", "error", err.Error())
+		return nil, err
+	}
+
+	usersConn, err := grpc.NewClient(fmt.Sprintf("%s%s", cfg.UsersServiceHost, cfg.UsersServiceGRPCPort),
+		opts...)
+
+	if err != nil {
+		logger.Logger.Error("error creating users service grpc client", "error", err.Error())
+		return nil, err
+	}
+
 	clients := &GRPCClients{
-		PostsServiceClient:     pb.NewPostsServiceClient(postsConn),
-		StatisticServiceClient: pb.NewStatisticServiceClient(statisticConn),
+		PostsServiceClient:         pb.NewPostsServiceClient(postsConn),
+		StatisticServiceClient:     pb.NewStatisticServiceClient(statisticConn),
+		NotificationsServiceClient: pb.NewNotificationsServiceClient(notificationsConn),
+		UsersServiceClient:         pb.NewUsersServiceClient(usersConn),
+		Health: map[string]healthpb.HealthClient{
+			"posts_service":         healthpb.NewHealthClient(postsConn),
+			"statistic_service":     healthpb.NewHealthClient(statisticConn),
+			"notifications_service": healthpb.NewHealthClient(notificationsConn),
+			"users_service":         healthpb.NewHealthClient(usersConn),
+		},
 	}
 
 	lc.Append(fx.Hook{
@@ -48,6 +104,14 @@ func NewGRPCClients(lc fx.Lifecycle, cfg *config.Config) (*GRPCClients, error) {
 				logger.Logger.Error("error closing statistic service grpc client", err.Error())
 				return err
 			}
+			if err := notificationsConn.Close(); err != nil {
+				logger.Logger.Error("error closing notifications service grpc client", "error", err.Error())
+				return err
+			}
+			if err := usersConn.Close(); err != nil {
+				logger.Logger.Error("error closing users service grpc client", "error", err.Error())
+				return err
+			}
 
 			return nil
 		},
@@ -0,0 +1,86 @@
+package health
+
+import (
+	"context"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/config"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/errors"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/clients"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"sync"
+	"time"
+)
+
+// Check is a dependency the service needs to serve requests.
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Checker aggregates the health of the backend services, the gateway is ready when all of
+// them are serving.
+type Checker struct {
+	checks  []Check
+	timeout time.Duration
+}
+
+func NewChecker(cfg *config.Config, c *clients.GRPCClients) *Checker {
+	checks := make([]Check, 0, len(c.Health))
+	for name, client := range c.Health {
+		checks = append(checks, Check{Name: name, Check: grpcCheck(client)})
+	}
+
+	return &Checker{checks: checks, timeout: cfg.HealthCheckTimeout}
+}
+
+// Report is the result of the readiness checks, failed checks hold their error.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Ready runs the checks concurrently, each of them is bounded by the check timeout.
+func (c *Checker) Ready(ctx context.Context) (*Report, bool) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	report := &Report{Status: "ok", Checks: make(map[string]string, len(c.checks))}
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := "ok"
+			if err := check.Check(ctx); err != nil {
+				res = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.Name] = res
+			if res != "ok" {
+				report.Status = "unavailable"
+			}
+		}()
+	}
+	wg.Wait()
+
+	return report, report.Status == "ok"
+}
+
+// grpcCheck asks the backend service for the overall status of its server.
+func grpcCheck(client healthpb.HealthClient) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			return err
+		}
+		if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			return errors.NotServingError{Status: resp.GetStatus().String()}
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type routeKey struct{}
+
+// HTTPMetrics records the rate, errors and duration of the handled requests per route.
+type HTTPMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func NewHTTPMetrics(reg prometheus.Registerer) *HTTPMetrics {
+	m := &HTTPMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of handled HTTP requests by route, method and status code.",
+		}, []string{"route", "method", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duration of handled HTTP requests by route and method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+	}
+	reg.MustRegister(m.requests, m.duration)
+
+	return m
+}
+
+// SetRoute names the route of the request when it is matched below the ServeMux,
+// e.g. by the grpc-gateway mux behind the /api/v2/ prefix.
+func SetRoute(ctx context.Context, route string) {
+	if r, ok := ctx.Value(routeKey{}).(*string); ok {
+		*r = route
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Middleware must wrap the ServeMux itself, the route is the pattern the mux matched.
+// Paths without a route are counted together so that they don't blow up the label set.
+func (m *HTTPMetrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		route := new(string)
+		rec := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+		r = r.WithContext(context.WithValue(r.Context(), routeKey{}, route))
+
+		next.ServeHTTP(rec, r)
+
+		if *route == "" {
+			*route = r.Pattern
+		}
+		if *route == "" {
+			*route = "unmatched"
+		}
+		m.requests.WithLabelValues(*route, r.Method, strconv.Itoa(rec.code)).Inc()
+		m.duration.WithLabelValues(*route, r.Method).Observe(time.Since(start).Seconds())
+	})
+}
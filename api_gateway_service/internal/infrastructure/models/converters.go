@@ -2,6 +2,7 @@ package models
 
 import (
 	pb "github.com/grigorovskiiy/soa-hse/protos"
+	"strings"
 )
 
 func (m *CreatePostRequest) ToPostsProto() *pb.PostDataRequest {
@@ -41,6 +42,20 @@ func FromProtoPostResponse(pb *pb.PostDataResponse) *GetPostResponse {
 		UpdatedAt:       pb.GetUpdatedAt().AsTime().Local(),
 		Tags:            pb.GetTags(),
 		UserID:          int(pb.GetUserId()),
+		LikedByMe:       pb.GetLikedByMe(),
+	}
+}
+
+func FromProtoFeedAuthor(pb *pb.UserResponse) *FeedAuthor {
+	displayName := strings.TrimSpace(pb.GetName() + " " + pb.GetSurname())
+	if displayName == "" {
+		displayName = pb.GetLogin()
+	}
+
+	return &FeedAuthor{
+		UserID:      int(pb.GetUserId()),
+		Login:       pb.GetLogin(),
+		DisplayName: displayName,
 	}
 }
 
@@ -137,3 +152,80 @@ func FromProtoCountResponse(pb *pb.CountResponse) *CountResponse {
 		Count: int32(pb.Count),
 	}
 }
+
+func (m *UserID) ToStatisticProto() *pb.UserID {
+	return &pb.UserID{
+		UserId: int32(m.UserID),
+	}
+}
+
+func FromProtoAuthorStatsResponse(pb *pb.AuthorStatsResponse) *AuthorStatsResponse {
+	return &AuthorStatsResponse{
+		ViewsCount:         int(pb.ViewsCount),
+		LikesCount:         int(pb.LikesCount),
+		CommentsCount:      int(pb.CommentsCount),
+		UniqueViewersCount: int(pb.UniqueViewersCount),
+		EngagementRate:     pb.EngagementRate,
+		ViewsDynamic:       fromProtoDynamic(pb.ViewsDynamic),
+		LikesDynamic:       fromProtoDynamic(pb.LikesDynamic),
+		CommentsDynamic:    fromProtoDynamic(pb.CommentsDynamic),
+		TopPostsByViews:    fromProtoPostIDs(pb.TopPostsByViews),
+		TopPostsByLikes:    fromProtoPostIDs(pb.TopPostsByLikes),
+		TopPostsByComments: fromProtoPostIDs(pb.TopPostsByComments),
+	}
+}
+
+func fromProtoDynamic(dyn []*pb.DynamicResponse) []*DynamicResponse {
+	return FromProtoDynamuicListResponse(&pb.DynamicListResponse{Dynamic: dyn}).Dynamic
+}
+
+func fromProtoPostIDs(posts []*pb.PostID) []int {
+	ids := make([]int, len(posts))
+	for i, p := range posts {
+		ids[i] = int(p.PostId)
+	}
+
+	return ids
+}
+
+func fromProtoNotification(n *pb.NotificationResponse) *NotificationResponse {
+	actorIDs := make([]int, len(n.ActorIds))
+	for i, id := range n.ActorIds {
+		actorIDs[i] = int(id)
+	}
+
+	return &NotificationResponse{
+		NotificationID: int(n.NotificationId),
+		Type:           n.Type,
+		PostID:         int(n.PostId),
+		ActorsCount:    int(n.ActorsCount),
+		ActorIDs:       actorIDs,
+		Text:           n.Text,
+		Read:           n.Read,
+		CreatedAt:      n.GetCreatedAt().AsTime().Local(),
+		UpdatedAt:      n.GetUpdatedAt().AsTime().Local(),
+	}
+}
+
+func FromProtoListNotificationsResponse(pb *pb.ListNotificationsResponse) *GetNotificationsResponse {
+	notifications := make([]*NotificationResponse, len(pb.Notifications))
+	for i, n := range pb.Notifications {
+		notifications[i] = fromProtoNotification(n)
+	}
+
+	return &GetNotificationsResponse{
+		Notifications: notifications,
+	}
+}
+
+func (m *MarkNotificationsReadRequest) ToNotificationsProto() *pb.MarkNotificationsReadRequest {
+	ids := make([]int32, len(m.NotificationIDs))
+	for i, id := range m.NotificationIDs {
+		ids[i] = int32(id)
+	}
+
+	return &pb.MarkNotificationsReadRequest{
+		NotificationIds: ids,
+		All:             m.All,
+	}
+}
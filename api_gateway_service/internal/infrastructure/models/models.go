@@ -51,6 +51,7 @@ type GetPostResponse struct {
 	UpdatedAt       time.Time `json:"updated_at"`
 	Tags            []string  `json:"tags"`
 	UserID          int       `json:"user_id"`
+	LikedByMe       bool      `json:"liked_by_me"`
 }
 
 type GetPostListResponse struct {
@@ -62,6 +63,28 @@ type PostCommentRequest struct {
 	Description string `json:"description"`
 }
 
+type FeedAuthor struct {
+	UserID      int    `json:"user_id"`
+	Login       string `json:"login"`
+	DisplayName string `json:"display_name"`
+}
+
+// FeedPost is a post with its author and counters. Parts which could not be loaded are
+// left empty and listed in Missing.
+type FeedPost struct {
+	GetPostResponse
+	Author        *FeedAuthor `json:"author"`
+	LikesCount    *int        `json:"likes_count"`
+	CommentsCount *int        `json:"comments_count"`
+	ViewsCount    *int        `json:"views_count"`
+	Missing       []string    `json:"missing,omitempty"`
+}
+
+type FeedResponse struct {
+	Posts   []*FeedPost `json:"posts"`
+	Partial bool        `json:"partial"`
+}
+
 type GetCommentResponse struct {
 	CommentID   int    `json:"comment_id"`
 	UserID      int    `json:"user_id"`
@@ -93,3 +116,42 @@ type TopTenResponse struct {
 type CountResponse struct {
 	Count int32 `json:"count"`
 }
+
+type UserID struct {
+	UserID int `json:"user_id"`
+}
+
+type AuthorStatsResponse struct {
+	ViewsCount         int                `json:"views_count"`
+	LikesCount         int                `json:"likes_count"`
+	CommentsCount      int                `json:"comments_count"`
+	UniqueViewersCount int                `json:"unique_viewers_count"`
+	EngagementRate     float64            `json:"engagement_rate"`
+	ViewsDynamic       []*DynamicResponse `json:"views_dynamic"`
+	LikesDynamic       []*DynamicResponse `json:"likes_dynamic"`
+	CommentsDynamic    []*DynamicResponse `json:"comments_dynamic"`
+	TopPostsByViews    []int              `json:"top_posts_by_views"`
+	TopPostsByLikes    []int              `json:"top_posts_by_likes"`
+	TopPostsByComments []int              `json:"top_posts_by_comments"`
+}
+
+type NotificationResponse struct {
+	NotificationID int       `json:"notification_id"`
+	Type           string    `json:"type"`
+	PostID         int       `json:"post_id"`
+	ActorsCount    int       `json:"actors_count"`
+	ActorIDs       []int     `json:"actor_ids"`
+	Text           string    `json:"text"`
+	Read           bool      `json:"read"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type GetNotificationsResponse struct {
+	Notifications []*NotificationResponse `json:"notifications"`
+}
+
+type MarkNotificationsReadRequest struct {
+	NotificationIDs []int `json:"notification_ids"`
+	All             bool  `json:"all"`
+}
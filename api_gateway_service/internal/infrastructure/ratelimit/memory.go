@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"context"
+	"go.uber.org/fx"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the buckets that are full again are dropped.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	period  time.Duration
+}
+
+// MemoryStore keeps the buckets of one gateway in memory.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+func NewMemoryStore(lc fx.Lifecycle) *MemoryStore {
+	s := &MemoryStore{buckets: make(map[string]*bucket)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go s.sweep(ctx)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+
+	return s
+}
+
+func (s *MemoryStore) Take(_ context.Context, keys []string, policy Policy, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	capacity, rate := float64(policy.Requests), policy.rate()
+	res := Result{Allowed: true, Remaining: policy.Requests}
+	buckets := make([]*bucket, len(keys))
+	for i, key := range keys {
+		b, ok := s.buckets[key]
+		if !ok {
+			b = &bucket{tokens: capacity, updated: now}
+			s.buckets[key] = b
+		}
+		b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*rate)
+		b.updated, b.period = now, policy.Period
+
+		if b.tokens < 1 {
+			res.Allowed = false
+			res.RetryAfter = max(res.RetryAfter, seconds((1-b.tokens)/rate))
+		}
+		buckets[i] = b
+	}
+
+	for _, b := range buckets {
+		if res.Allowed {
+			b.tokens--
+		}
+		res.Remaining = min(res.Remaining, int(b.tokens))
+		res.Reset = max(res.Reset, seconds((capacity-b.tokens)/rate))
+	}
+
+	return res, nil
+}
+
+// sweep drops the buckets untouched for their period, they are full and equal to new ones.
+func (s *MemoryStore) sweep(ctx context.Context) {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for key, b := range s.buckets {
+				if now.Sub(b.updated) > b.period {
+					delete(s.buckets, key)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,146 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/config"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/errors"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/logger"
+	"go.uber.org/fx"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Module limits the requests with token buckets kept in memory.
+var Module = fx.Module("ratelimit",
+	fx.Provide(
+		NewMemoryStore,
+		func(s *MemoryStore) Store {
+			return s
+		},
+		NewLimiter,
+	),
+)
+
+// Policy allows Requests per Period, all of them may be made at once.
+type Policy struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParsePolicy parses "<requests>/<period>", e.g. 5/1m.
+func ParsePolicy(s string) (Policy, error) {
+	requests, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return Policy{}, errors.InvalidRateLimitPolicyError{Policy: s}
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n < 0 {
+		return Policy{}, errors.InvalidRateLimitPolicyError{Policy: s}
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Policy{}, errors.InvalidRateLimitPolicyError{Policy: s}
+	}
+
+	return Policy{Requests: n, Period: d}, nil
+}
+
+// rate is the number of tokens added to the bucket per second.
+func (p Policy) rate() float64 {
+	return float64(p.Requests) / p.Period.Seconds()
+}
+
+// Result is the state of the buckets after a request, the tightest of them.
+type Result struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is the time until the next token of a denied request.
+	RetryAfter time.Duration
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+}
+
+// Store keeps the token buckets. Take takes a token from the bucket of every key only if all of
+// them have one, a denied request costs nothing. It must be atomic, so that a shared store such
+// as Redis (with a script doing the same arithmetic) can be used by several gateways.
+type Store interface {
+	Take(ctx context.Context, keys []string, policy Policy, now time.Time) (Result, error)
+}
+
+type route struct {
+	key     string
+	method  string
+	pattern string
+	policy  Policy
+}
+
+// Limiter picks the policy of the route of a request and takes a token of the client from
+// the bucket of the route.
+type Limiter struct {
+	store  Store
+	def    Policy
+	routes []route
+}
+
+func NewLimiter(cfg *config.Config, store Store) (*Limiter, error) {
+	def, err := ParsePolicy(cfg.RateLimitDefault)
+	if err != nil {
+		logger.Logger.Error("parse default rate limit error", "error", err.Error())
+		return nil, err
+	}
+
+	routes := make([]route, 0, len(cfg.RateLimits))
+	for key, s := range cfg.RateLimits {
+		policy, err := ParsePolicy(s)
+		if err != nil {
+			logger.Logger.Error("parse rate limit error", "route", key, "error", err.Error())
+			return nil, err
+		}
+
+		r := route{key: key, pattern: key, policy: policy}
+		if method, pattern, ok := strings.Cut(key, " "); ok {
+			r.method, r.pattern = method, pattern
+		}
+		routes = append(routes, r)
+	}
+
+	return &Limiter{store: store, def: def, routes: routes}, nil
+}
+
+// policy returns the policy of the longest matching route, the default policy has the key "*".
+func (l *Limiter) policy(method, p string) (string, Policy) {
+	key, policy := "*", l.def
+	matched := ""
+	for _, r := range l.routes {
+		if r.method != "" && r.method != method {
+			continue
+		}
+		if ok, _ := path.Match(r.pattern, p); ok && len(r.key) > len(matched) {
+			key, policy, matched = r.key, r.policy, r.key
+		}
+	}
+
+	return key, policy
+}
+
+// Allow takes a token of every client of the request, e.g. of its user and of its IP, each has
+// a bucket of its own. The request is allowed only if all of them have a token left, otherwise
+// no token is taken. The policy is returned for the RateLimit headers, a policy without
+// requests is not limited.
+func (l *Limiter) Allow(ctx context.Context, method, path string, clients ...string) (Policy, Result, error) {
+	key, policy := l.policy(method, path)
+	if policy.Requests == 0 {
+		return policy, Result{Allowed: true}, nil
+	}
+
+	keys := make([]string, len(clients))
+	for i, client := range clients {
+		keys[i] = key + "|" + client
+	}
+
+	res, err := l.store.Take(ctx, keys, policy, time.Now())
+	return policy, res, err
+}
@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/config"
+	svcErrors "github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/errors"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/logger"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clfTimeLayout is the time format of the Common Log Format.
+const clfTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// responseRecorder remembers the status and the size of the response for the access log.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// accessEntry is one handled request.
+type accessEntry struct {
+	r       *http.Request
+	status  int
+	bytes   int
+	latency time.Duration
+	ip      string
+	start   time.Time
+}
+
+// ClientIP is the address of the client. X-Forwarded-For is used only when the gateway runs
+// behind a trusted proxy, and only its last address, the one appended by that proxy: the
+// addresses before it are sent by the client and may be forged.
+func ClientIP(r *http.Request, trustProxyHeaders bool) string {
+	if trustProxyHeaders {
+		if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+			addrs := strings.Split(values[len(values)-1], ",")
+			if ip := strings.TrimSpace(addrs[len(addrs)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// AccessLogMiddleware logs every request with its status, latency, response size and client IP
+// in the format of cfg. It must wrap the ServeMux itself so that the route of the request is known.
+func AccessLogMiddleware(cfg config.AccessLogConfig) (func(http.Handler) http.Handler, error) {
+	var write func(e *accessEntry)
+	switch cfg.AccessLogFormat {
+	case "json":
+		write = writeJSONEntry
+	case "common":
+		write = func(e *accessEntry) { writeCLFEntry(os.Stdout, e, false) }
+	case "combined":
+		write = func(e *accessEntry) { writeCLFEntry(os.Stdout, e, true) }
+	default:
+		logger.Logger.Error("unknown access log format", "format", cfg.AccessLogFormat)
+		return nil, svcErrors.UnknownAccessLogFormatError{Format: cfg.AccessLogFormat}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			e := &accessEntry{
+				r:       r,
+				status:  rec.status,
+				bytes:   rec.bytes,
+				latency: time.Since(start),
+				ip:      ClientIP(r, cfg.TrustProxyHeaders),
+				start:   start,
+			}
+
+			slow := cfg.SlowRequestThreshold > 0 && e.latency > cfg.SlowRequestThreshold
+			if slow {
+				logger.FromContext(r.Context()).Warn("slow request",
+					"method", r.Method,
+					"path", r.URL.Path,
+					"route", r.Pattern,
+					"status", e.status,
+					"latency_ms", e.latency.Milliseconds(),
+					"threshold_ms", cfg.SlowRequestThreshold.Milliseconds())
+			}
+
+			if slow || e.status >= http.StatusInternalServerError || rand.Float64() < cfg.SampleRate(r.URL.Path) {
+				write(e)
+			}
+		})
+	}, nil
+}
+
+func writeJSONEntry(e *accessEntry) {
+	logger := logger.FromContext(e.r.Context())
+	// The user is known only after AuthMiddleware has checked the token.
+	if userID := e.r.Header.Get("UserID"); userID != "" {
+		logger = logger.With("user_id", userID)
+	}
+
+	logger.Info("access",
+		"method", e.r.Method,
+		"path", e.r.URL.Path,
+		"route", e.r.Pattern,
+		"status", e.status,
+		"bytes", e.bytes,
+		"latency_ms", e.latency.Milliseconds(),
+		"client_ip", e.ip,
+		"user_agent", e.r.UserAgent())
+}
+
+// writeCLFEntry writes the entry in the Common Log Format, the Combined Log Format adds the
+// referer and the user agent.
+func writeCLFEntry(w io.Writer, e *accessEntry, combined bool) {
+	line := fmt.Sprintf("%s - %s [%s] %s %d %s",
+		e.ip,
+		clfField(e.r.Header.Get("UserID")),
+		e.start.Format(clfTimeLayout),
+		strconv.Quote(e.r.Method+" "+e.r.RequestURI+" "+e.r.Proto),
+		e.status,
+		clfField(bytesField(e.bytes)))
+	if combined {
+		line += " " + strconv.Quote(clfField(e.r.Referer())) + " " + strconv.Quote(clfField(e.r.UserAgent()))
+	}
+
+	_, _ = io.WriteString(w, line+"\n")
+}
+
+func bytesField(n int) string {
+	if n == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(n)
+}
+
+func clfField(v string) string {
+	if v == "" {
+		return "-"
+	}
+
+	return v
+}
@@ -1,14 +1,18 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/logger"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"strconv"
+	"time"
 )
 
 type Claims struct {
@@ -20,25 +24,37 @@ type Claims struct {
 
 var jwtKey = []byte("secret-key")
 
-func JWTVerify(r *http.Request) error {
-	tokenString := r.Header.Get("Authorization")
-	if tokenString == "" {
-		logger.Logger.Error("token is empty")
-		return errors.New("token is empty")
-	}
-
+// parseClaims checks the signature of the token and returns its claims.
+func parseClaims(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			logger.Logger.Error("token sign error")
 			return nil, errors.New(fmt.Sprintf("unexpected signing method: %v", token.Header["alg"]))
 		}
 
 		return jwtKey, nil
 	})
 
-	if err != nil || !token.Valid {
-		logger.Logger.Error("token is invalid")
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("token is invalid")
+	}
+
+	return claims, nil
+}
+
+func JWTVerify(r *http.Request) error {
+	tokenString := r.Header.Get("Authorization")
+	if tokenString == "" {
+		logger.FromContext(r.Context()).Error("token is empty")
+		return errors.New("token is empty")
+	}
+
+	claims, err := parseClaims(tokenString)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("token is invalid", "error", err.Error())
 		return errors.New("token is invalid")
 	}
 
@@ -53,12 +69,12 @@ func AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		err := JWTVerify(r)
 		if err != nil {
-			logger.Logger.Error("jwt verify erorr", "error", err.Error())
+			logger.FromContext(r.Context()).Error("jwt verify erorr", "error", err.Error())
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(logger.WithUserID(r.Context(), r.Header.Get("UserID"))))
 	})
 }
 
@@ -72,6 +88,26 @@ func MethodMiddleware(method string, next http.Handler) http.Handler {
 	})
 }
 
+// DeprecatedMiddleware marks responses of v1 routes as deprecated and points to the v2 route.
+func DeprecatedMiddleware(successor string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// TimeoutMiddleware cancels the request context after the timeout of its path, the deadline
+// is passed on to the backend services with the gRPC calls.
+func TimeoutMiddleware(timeout func(path string) time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout(r.URL.Path))
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func ProxyMiddleware(targetHost, targetPort string) func(http.Handler) http.Handler {
 	targetURL := fmt.Sprintf("%s%s", targetHost, targetPort)
 	proxy := httputil.NewSingleHostReverseProxy(&url.URL{
@@ -79,8 +115,15 @@ func ProxyMiddleware(targetHost, targetPort string) func(http.Handler) http.Hand
 		Host:   targetURL,
 	})
 
+	// The transport passes the trace context on to users_service.
+	proxy.Transport = otelhttp.NewTransport(http.DefaultTransport)
+
 	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
-		logger.Logger.Error("proxy error", "error", err.Error())
+		logger.FromContext(r.Context()).Error("proxy error", "error", err.Error())
+		if errors.Is(err, context.DeadlineExceeded) {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
 		w.WriteHeader(http.StatusBadGateway)
 	}
 
@@ -92,7 +135,7 @@ func ProxyMiddleware(targetHost, targetPort string) func(http.Handler) http.Hand
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			logger.Logger.Info("proxying request",
+			logger.FromContext(r.Context()).Info("proxying request",
 				"method", r.Method,
 				"path", r.URL.Path,
 				"target", targetURL)
@@ -101,14 +144,34 @@ func ProxyMiddleware(targetHost, targetPort string) func(http.Handler) http.Hand
 	}
 }
 
-func LoggerMiddleware(next http.Handler) http.Handler {
+// maxRequestIDLen bounds the accepted X-Request-ID, longer or non-printable IDs are replaced.
+const maxRequestIDLen = 128
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// RequestIDMiddleware accepts the X-Request-ID of the client or generates a new one. The ID is
+// returned in the response and passed on to the backend services with the request header,
+// the gRPC metadata and the Kafka events.
+func RequestIDMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		logger := logger.Logger.With(
-			"path", r.URL.Path,
-			"method", r.Method,
-		)
-		logger.Info("request started")
-		defer logger.Info("request finished")
-		next.ServeHTTP(w, r)
+		id := r.Header.Get(logger.RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		r.Header.Set(logger.RequestIDHeader, id)
+		w.Header().Set(logger.RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logger.WithRequestID(r.Context(), id)))
 	})
 }
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/ratelimit"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ceilSeconds rounds d up to whole seconds for the headers.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// rateLimitClients are the client IP and the user of a valid token. Both are limited, so that
+// neither several accounts on one IP nor one account on several IPs get more requests.
+func rateLimitClients(r *http.Request, trustProxyHeaders bool) []string {
+	clients := []string{"ip:" + ClientIP(r, trustProxyHeaders)}
+	if tokenString := r.Header.Get("Authorization"); tokenString != "" {
+		if claims, err := parseClaims(tokenString); err == nil {
+			clients = append(clients, "user:"+strconv.Itoa(claims.UserID))
+		}
+	}
+
+	return clients
+}
+
+// RateLimitMiddleware answers 429 with Retry-After once the client has used up the requests of
+// the route, every limited response carries the RateLimit-* headers. The requests are let
+// through if the store fails.
+func RateLimitMiddleware(l *ratelimit.Limiter, trustProxyHeaders bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clients := rateLimitClients(r, trustProxyHeaders)
+			policy, res, err := l.Allow(r.Context(), r.Method, r.URL.Path, clients...)
+			if err != nil {
+				logger.FromContext(r.Context()).Error("rate limit store error", "clients", clients, "error", err.Error())
+				next.ServeHTTP(w, r)
+				return
+			}
+			if policy.Requests == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(policy.Requests))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", ceilSeconds(res.Reset))
+			h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%s", policy.Requests, ceilSeconds(policy.Period)))
+
+			if !res.Allowed {
+				logger.FromContext(r.Context()).Warn("rate limit exceeded",
+					"clients", clients, "method", r.Method, "path", r.URL.Path)
+				h.Set("Retry-After", ceilSeconds(res.RetryAfter))
+				h.Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusTooManyRequests)
+				_ = json.NewEncoder(w).Encode("rate limit exceeded")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
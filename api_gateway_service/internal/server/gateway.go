@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/clients"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/metrics"
+	pb "github.com/grigorovskiiy/soa-hse/protos"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/protobuf/encoding/protojson"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// headerMatcher drops the Grpc-Metadata- headers that the default matcher passes on as metadata.
+func headerMatcher(key string) (string, bool) {
+	if strings.HasPrefix(textproto.CanonicalMIMEHeaderKey(key), runtime.MetadataHeaderPrefix) {
+		return "", false
+	}
+
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// NewGatewayMux builds the /api/v2 routes from the google.api.http annotations in protos/soa.proto.
+func NewGatewayMux(c *clients.GRPCClients) (*runtime.ServeMux, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				UseProtoNames:   true,
+				EmitUnpopulated: true,
+			},
+			UnmarshalOptions: protojson.UnmarshalOptions{
+				DiscardUnknown: true,
+			},
+		}),
+		// The client must not pass metadata such as user_id or x-request-id itself, the checked user
+		// and the request ID are set by the interceptor of the gRPC clients.
+		runtime.WithIncomingHeaderMatcher(headerMatcher),
+		// The route of the metrics is the annotated path, not the /api/v2/ prefix.
+		runtime.WithMiddlewares(func(next runtime.HandlerFunc) runtime.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+				if pattern, ok := runtime.HTTPPathPattern(r.Context()); ok {
+					metrics.SetRoute(r.Context(), pattern)
+				}
+				next(w, r, pathParams)
+			}
+		}),
+	)
+
+	ctx := context.Background()
+	if err := pb.RegisterPostsServiceHandlerClient(ctx, mux, c.PostsServiceClient); err != nil {
+		logger.Logger.Error("register posts service gateway error", "error", err.Error())
+		return nil, err
+	}
+	if err := pb.RegisterStatisticServiceHandlerClient(ctx, mux, c.StatisticServiceClient); err != nil {
+		logger.Logger.Error("register statistic service gateway error", "error", err.Error())
+		return nil, err
+	}
+	if err := pb.RegisterNotificationsServiceHandlerClient(ctx, mux, c.NotificationsServiceClient); err != nil {
+		logger.Logger.Error("register notifications service gateway error", "error", err.Error())
+		return nil, err
+	}
+	if err := pb.RegisterUsersServiceHandlerClient(ctx, mux, c.UsersServiceClient); err != nil {
+		logger.Logger.Error("register users service gateway error", "error", err.Error())
+		return nil, err
+	}
+
+	return mux, nil
+}
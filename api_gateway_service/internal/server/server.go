@@ -5,124 +5,197 @@ import (
 	"errors"
 	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/application"
 	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/config"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/health"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/metrics"
+	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/infrastructure/ratelimit"
 	"github.com/grigorovskiiy/soa-hse/api_gateway_service/internal/middleware"
+	pb "github.com/grigorovskiiy/soa-hse/protos"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 	"net/http"
 )
 
-func NewServer(a *application.GatewayApp, cfg *config.Config) *http.Server {
+func NewServer(a *application.GatewayApp, gateway *runtime.ServeMux, cfg *config.Config, tp trace.TracerProvider, m *metrics.HTTPMetrics,
+	checker *health.Checker, limiter *ratelimit.Limiter) (*http.Server, error) {
 	mux := http.NewServeMux()
 
+	// v1 routes are kept as aliases of /api/v2, their responses carry the Deprecation header.
+	deprecated := func(pattern, successor string, handler http.Handler) {
+		mux.Handle(pattern, middleware.DeprecatedMiddleware(successor, handler))
+	}
+
 	mux.Handle("/register",
 		middleware.ProxyMiddleware(cfg.UsersServiceHost, cfg.UsersServicePort)(
-			middleware.LoggerMiddleware(
-				middleware.MethodMiddleware(http.MethodPost, http.HandlerFunc(a.Register)),
-			)))
+			middleware.MethodMiddleware(http.MethodPost, http.HandlerFunc(a.Register))))
 
 	mux.Handle("/login",
 		middleware.ProxyMiddleware(cfg.UsersServiceHost, cfg.UsersServicePort)(
-			middleware.LoggerMiddleware(
-				middleware.MethodMiddleware(http.MethodPost, http.HandlerFunc(a.Register)),
-			)))
+			middleware.MethodMiddleware(http.MethodPost, http.HandlerFunc(a.Register))))
 
-	mux.Handle("/get_user_info",
+	deprecated("/get_user_info", "/api/v2/users/me",
 		middleware.ProxyMiddleware(cfg.UsersServiceHost, cfg.UsersServicePort)(
-			middleware.LoggerMiddleware(
-				middleware.MethodMiddleware(http.MethodPost, http.HandlerFunc(a.GetUserInfo)),
-			)))
+			middleware.MethodMiddleware(http.MethodPost, http.HandlerFunc(a.GetUserInfo))))
 
-	mux.Handle("/update_user_info",
+	deprecated("/update_user_info", "/api/v2/users/me",
 		middleware.ProxyMiddleware(cfg.UsersServiceHost, cfg.UsersServicePort)(
-			middleware.LoggerMiddleware(
-				middleware.MethodMiddleware(http.MethodPost, http.HandlerFunc(a.UpdateUserInfo)),
-			)))
-
-	mux.Handle("/create_post",
-		middleware.LoggerMiddleware(
-			middleware.MethodMiddleware(http.MethodPost,
-				middleware.AuthMiddleware(http.HandlerFunc(a.CreatePost)))))
-
-	mux.Handle("/delete_post",
-		middleware.LoggerMiddleware(
-			middleware.MethodMiddleware(http.MethodDelete,
-				middleware.AuthMiddleware(http.HandlerFunc(a.DeletePost)))))
-
-	mux.Handle("/update_post",
-		middleware.LoggerMiddleware(
-			middleware.MethodMiddleware(http.MethodPut,
-				middleware.AuthMiddleware(http.HandlerFunc(a.UpdatePost)))))
-
-	mux.Handle("/get_post",
-		middleware.LoggerMiddleware(
-			middleware.MethodMiddleware(http.MethodGet,
-				middleware.AuthMiddleware(http.HandlerFunc(a.GetPost)))))
-
-	mux.Handle("/get_post_list",
-		middleware.LoggerMiddleware(
-			middleware.MethodMiddleware(http.MethodGet,
-				middleware.AuthMiddleware(http.HandlerFunc(a.GetPostList)))))
-
-	mux.Handle("/post_comment",
-		middleware.LoggerMiddleware(
-			middleware.MethodMiddleware(http.MethodPost,
-				middleware.AuthMiddleware(http.HandlerFunc(a.PostComment)))),
+			middleware.MethodMiddleware(http.MethodPost, http.HandlerFunc(a.UpdateUserInfo))))
+
+	deprecated("/create_post", "/api/v2/posts",
+		middleware.MethodMiddleware(http.MethodPost,
+			middleware.AuthMiddleware(http.HandlerFunc(a.CreatePost))))
+
+	deprecated("/delete_post", "/api/v2/posts/{post_id}",
+		middleware.MethodMiddleware(http.MethodDelete,
+			middleware.AuthMiddleware(http.HandlerFunc(a.DeletePost))))
+
+	deprecated("/update_post", "/api/v2/posts/{post_id}",
+		middleware.MethodMiddleware(http.MethodPut,
+			middleware.AuthMiddleware(http.HandlerFunc(a.UpdatePost))))
+
+	deprecated("/get_post", "/api/v2/posts/{post_id}",
+		middleware.MethodMiddleware(http.MethodGet,
+			middleware.AuthMiddleware(http.HandlerFunc(a.GetPost))))
+
+	deprecated("/get_post_list", "/api/v2/posts",
+		middleware.MethodMiddleware(http.MethodGet,
+			middleware.AuthMiddleware(http.HandlerFunc(a.GetPostList))))
+
+	deprecated("/post_comment", "/api/v2/posts/{post_id}/comments",
+		middleware.MethodMiddleware(http.MethodPost,
+			middleware.AuthMiddleware(http.HandlerFunc(a.PostComment))),
 	)
 
-	mux.Handle("/post_like",
-		middleware.LoggerMiddleware(
-			middleware.MethodMiddleware(http.MethodPost,
-				middleware.AuthMiddleware(http.HandlerFunc(a.PostLike)))))
+	deprecated("/post_like", "/api/v2/posts/{post_id}/likes",
+		middleware.MethodMiddleware(http.MethodPost,
+			middleware.AuthMiddleware(http.HandlerFunc(a.PostLike))))
+
+	deprecated("/post_view", "/api/v2/posts/{post_id}/views",
+		middleware.MethodMiddleware(http.MethodPost,
+			middleware.AuthMiddleware(http.HandlerFunc(a.PostView))))
+
+	deprecated("/get_comment_list", "/api/v2/posts/{post_id}/comments",
+		middleware.MethodMiddleware(http.MethodGet,
+			middleware.AuthMiddleware(http.HandlerFunc(a.GetCommentList))))
+
+	deprecated("/stats/{metric}/{kind}", "/api/v2/posts/{post_id}/stats/{metric}/{kind}",
+		middleware.MethodMiddleware(http.MethodGet,
+			middleware.AuthMiddleware(http.HandlerFunc(a.GetStats))))
+
+	deprecated("/get_comments_count", "/api/v2/posts/{post_id}/stats/{metric}/{kind}",
+		middleware.MethodMiddleware(http.MethodGet,
+			middleware.AuthMiddleware(http.HandlerFunc(a.GetCommentsCount))))
+
+	deprecated("/get_likes_count", "/api/v2/posts/{post_id}/stats/{metric}/{kind}",
+		middleware.MethodMiddleware(http.MethodGet,
+			middleware.AuthMiddleware(http.HandlerFunc(a.GetLikesCount))))
+
+	deprecated("/get_views_count", "/api/v2/posts/{post_id}/stats/{metric}/{kind}",
+		middleware.MethodMiddleware(http.MethodGet,
+			middleware.AuthMiddleware(http.HandlerFunc(a.GetViewsCount))))
+
+	deprecated("/get_comments_dynamic", "/api/v2/posts/{post_id}/stats/{metric}/{kind}",
+		middleware.MethodMiddleware(http.MethodGet,
+			middleware.AuthMiddleware(http.HandlerFunc(a.GetCommentsDynamic))))
 
-	mux.Handle("/post_view",
-		middleware.LoggerMiddleware(
-			middleware.MethodMiddleware(http.MethodPost,
-				middleware.AuthMiddleware(http.HandlerFunc(a.PostView)))))
+	deprecated("/get_likes_dynamic", "/api/v2/posts/{post_id}/stats/{metric}/{kind}",
+		middleware.MethodMiddleware(http.MethodGet,
+			middleware.AuthMiddleware(http.HandlerFunc(a.GetLikesDynamic))))
 
-	mux.Handle("/get_comment_list",
-		middleware.LoggerMiddleware(
-			middleware.MethodMiddleware(http.MethodGet,
-				middleware.AuthMiddleware(http.HandlerFunc(a.GetCommentList)))))
+	deprecated("/get_views_dynamic", "/api/v2/posts/{post_id}/stats/{metric}/{kind}",
+		middleware.MethodMiddleware(http.MethodGet,
+			middleware.AuthMiddleware(http.HandlerFunc(a.GetViewsDynamic))))
 
-	mux.Handle("/get_comments_count",
-		middleware.LoggerMiddleware(
-			middleware.MethodMiddleware(http.MethodGet, http.HandlerFunc(a.GetCommentsCount))))
+	deprecated("/get_unique_viewers_count", "/api/v2/posts/{post_id}/stats/{metric}/{kind}",
+		middleware.MethodMiddleware(http.MethodGet,
+			middleware.AuthMiddleware(http.HandlerFunc(a.GetUniqueViewersCount))))
 
-	mux.Handle("/get_likes_count",
-		middleware.LoggerMiddleware(
-			middleware.MethodMiddleware(http.MethodGet, http.HandlerFunc(a.GetLikesCount))))
+	deprecated("/get_unique_likers_count", "/api/v2/posts/{post_id}/stats/{metric}/{kind}",
+		middleware.MethodMiddleware(http.MethodGet,
+			middleware.AuthMiddleware(http.HandlerFunc(a.GetUniqueLikersCount))))
 
-	mux.Handle("/get_views_count",
-		middleware.LoggerMiddleware(
-			middleware.MethodMiddleware(http.MethodGet, http.HandlerFunc(a.GetViewsCount))))
+	deprecated("/get_unique_viewers_dynamic", "/api/v2/posts/{post_id}/stats/{metric}/{kind}",
+		middleware.MethodMiddleware(http.MethodGet,
+			middleware.AuthMiddleware(http.HandlerFunc(a.GetUniqueViewersDynamic))))
 
-	mux.Handle("/get_comments_dynamic",
-		middleware.LoggerMiddleware(
-			middleware.MethodMiddleware(http.MethodGet, http.HandlerFunc(a.GetCommentsDynamic))))
+	deprecated("/get_unique_likers_dynamic", "/api/v2/posts/{post_id}/stats/{metric}/{kind}",
+		middleware.MethodMiddleware(http.MethodGet,
+			middleware.AuthMiddleware(http.HandlerFunc(a.GetUniqueLikersDynamic))))
 
-	mux.Handle("/get_likes_dynamic",
-		middleware.LoggerMiddleware(
-			middleware.MethodMiddleware(http.MethodGet, http.HandlerFunc(a.GetLikesDynamic))))
+	deprecated("/get_top_ten_posts", "/api/v2/stats/top/posts",
+		middleware.MethodMiddleware(http.MethodGet,
+			middleware.AuthMiddleware(http.HandlerFunc(a.GetTopTenPosts))))
 
-	mux.Handle("/get_views_dynamic",
-		middleware.LoggerMiddleware(
-			middleware.MethodMiddleware(http.MethodGet, http.HandlerFunc(a.GetViewsDynamic))))
+	deprecated("/get_top_ten_users", "/api/v2/stats/top/users",
+		middleware.MethodMiddleware(http.MethodGet,
+			middleware.AuthMiddleware(http.HandlerFunc(a.GetTopTenUsers))))
 
-	mux.Handle("/get_top_ten_posts",
-		middleware.LoggerMiddleware(
-			middleware.MethodMiddleware(http.MethodGet, http.HandlerFunc(a.GetTopTenPosts))))
+	deprecated("/get_author_stats", "/api/v2/users/{user_id}/stats",
+		middleware.MethodMiddleware(http.MethodGet,
+			middleware.AuthMiddleware(http.HandlerFunc(a.GetAuthorStats))))
 
-	mux.Handle("/get_top_ten_users",
-		middleware.LoggerMiddleware(
-			middleware.MethodMiddleware(http.MethodGet, http.HandlerFunc(a.GetTopTenUsers))))
+	deprecated("/get_notifications", "/api/v2/notifications",
+		middleware.MethodMiddleware(http.MethodGet,
+			middleware.AuthMiddleware(http.HandlerFunc(a.GetNotifications))))
+
+	deprecated("/mark_notifications_read", "/api/v2/notifications/read",
+		middleware.MethodMiddleware(http.MethodPost,
+			middleware.AuthMiddleware(http.HandlerFunc(a.MarkNotificationsRead))))
+
+	deprecated("/get_unread_notifications_count", "/api/v2/notifications/unread_count",
+		middleware.MethodMiddleware(http.MethodGet,
+			middleware.AuthMiddleware(http.HandlerFunc(a.GetUnreadNotificationsCount))))
+
+	// Routes of the backend gRPC services are generated from protos/soa.proto.
+	mux.Handle("/api/v2/", middleware.AuthMiddleware(gateway))
+
+	// The feed is composed from several services, so it is not generated from the protos.
+	mux.Handle("GET /api/v2/feed",
+		middleware.AuthMiddleware(http.HandlerFunc(a.GetFeed)))
+
+	// Registration and login are the only /api/v2 routes without a token.
+	mux.Handle("POST /api/v2/users", gateway)
+
+	mux.Handle("POST /api/v2/users/login", gateway)
+
+	mux.HandleFunc("GET /api/v2/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(pb.OpenAPI)
+	})
+
+	mux.Handle("/swagger/v2/", httpSwagger.Handler(httpSwagger.URL("/api/v2/openapi.json")))
 
 	mux.Handle("/swagger/", httpSwagger.Handler(httpSwagger.URL("swagger/swagger/doc.json")))
 
-	return &http.Server{
-		Addr:    cfg.GatewayServicePort,
-		Handler: mux,
+	// Readiness of the gateway aggregates the grpc.health.v1 status of the backend services.
+	mux.HandleFunc("GET /healthz", health.Liveness)
+	mux.HandleFunc("GET /readyz", checker.Readiness)
+
+	accessLog, err := middleware.AccessLogMiddleware(cfg.AccessLogConfig)
+	if err != nil {
+		return nil, err
 	}
 
+	var limited http.Handler = mux
+	if cfg.RateLimitEnabled {
+		limited = middleware.RateLimitMiddleware(limiter, cfg.TrustProxyHeaders)(mux)
+	}
+
+	// The access log and the metrics need the route matched by mux, so they wrap it directly.
+	handler := middleware.TimeoutMiddleware(cfg.RouteTimeout,
+		middleware.RequestIDMiddleware(
+			m.Middleware(accessLog(limited))))
+
+	return &http.Server{
+		Addr: cfg.GatewayServicePort,
+		Handler: otelhttp.NewHandler(handler, "api-gateway",
+			otelhttp.WithTracerProvider(tp),
+			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+				return r.Method + " " + r.URL.Path
+			})),
+	}, nil
 }
 
 func RunServer(lc fx.Lifecycle, server *http.Server) error {
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/application"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/config"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/infrastructure/db"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/infrastructure/health"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/infrastructure/kafka"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/infrastructure/metrics"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/infrastructure/repository"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/infrastructure/tracing"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/server"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/service"
+	"github.com/joho/godotenv"
+	"go.uber.org/fx"
+)
+
+func init() {
+	if err := godotenv.Load(); err != nil {
+		logger.Logger.Error("env file is not found")
+	}
+}
+
+func main() {
+	addOpts := fx.Options(
+		fx.Provide(config.NewConfig),
+		fx.Provide(tracing.NewTracerProvider),
+		metrics.Module,
+		health.Module,
+		fx.Provide(db.InitDb),
+		fx.Provide(repository.NewNRepository),
+		fx.Provide(func(r *repository.NRepository) service.NotificationsRepository {
+			return r
+		}),
+		fx.Provide(service.NewService),
+		fx.Provide(func(s *service.Service) application.NotificationsService {
+			return s
+		}),
+		fx.Provide(func(s *service.Service) kafka.EventsHandler {
+			return s
+		}),
+		fx.Provide(kafka.NewConsumer),
+		fx.Provide(application.NewNotificationsApp),
+		fx.Provide(server.NewServer),
+		fx.Invoke(server.RunServer, kafka.RunConsumer),
+	)
+
+	fx.New(addOpts).Run()
+}
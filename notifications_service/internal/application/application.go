@@ -0,0 +1,102 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/infrastructure/logger"
+	pb "github.com/grigorovskiiy/soa-hse/protos"
+	"google.golang.org/grpc/metadata"
+	"strconv"
+)
+
+type NotificationsService interface {
+	GetNotifications(context.Context, *pb.PaginatedListRequest, int32) (*pb.ListNotificationsResponse, error)
+	MarkNotificationsRead(context.Context, *pb.MarkNotificationsReadRequest, int32) error
+	GetUnreadNotificationsCount(context.Context, int32) (*pb.CountResponse, error)
+}
+
+type NotificationsServiceApp struct {
+	pb.UnimplementedNotificationsServiceServer
+	NotificationsService NotificationsService
+}
+
+func NewNotificationsApp(nS NotificationsService) *NotificationsServiceApp {
+	return &NotificationsServiceApp{NotificationsService: nS}
+}
+
+func (s *NotificationsServiceApp) GetNotifications(ctx context.Context, pb *pb.PaginatedListRequest) (*pb.ListNotificationsResponse, error) {
+	logger := logger.FromContext(ctx).With("method", "GetNotifications")
+	logger.Info("notifications grpc request started")
+
+	userID, err := GetUserID(ctx)
+	if err != nil {
+		logger.Error("error getting userID from ctx", "error", err.Error())
+		return nil, err
+	}
+
+	notifications, err := s.NotificationsService.GetNotifications(ctx, pb, userID)
+	if err != nil {
+		logger.Error("get notifications error", "error", err.Error())
+		return nil, err
+	}
+
+	logger.Info("notifications grpc request completed")
+	return notifications, nil
+}
+
+func (s *NotificationsServiceApp) MarkNotificationsRead(ctx context.Context, pb *pb.MarkNotificationsReadRequest) (*empty.Empty, error) {
+	logger := logger.FromContext(ctx).With("method", "MarkNotificationsRead")
+	logger.Info("notifications grpc request started")
+
+	userID, err := GetUserID(ctx)
+	if err != nil {
+		logger.Error("error getting userID from ctx", "error", err.Error())
+		return nil, err
+	}
+
+	if err = s.NotificationsService.MarkNotificationsRead(ctx, pb, userID); err != nil {
+		logger.Error("mark notifications read error", "error", err.Error())
+		return nil, err
+	}
+
+	logger.Info("notifications grpc request completed")
+	return &empty.Empty{}, nil
+}
+
+func (s *NotificationsServiceApp) GetUnreadNotificationsCount(ctx context.Context, _ *empty.Empty) (*pb.CountResponse, error) {
+	logger := logger.FromContext(ctx).With("method", "GetUnreadNotificationsCount")
+	logger.Info("notifications grpc request started")
+
+	userID, err := GetUserID(ctx)
+	if err != nil {
+		logger.Error("error getting userID from ctx", "error", err.Error())
+		return nil, err
+	}
+
+	count, err := s.NotificationsService.GetUnreadNotificationsCount(ctx, userID)
+	if err != nil {
+		logger.Error("get unread notifications count error", "error", err.Error())
+		return nil, err
+	}
+
+	logger.Info("notifications grpc request completed")
+	return count, nil
+}
+
+func GetUserID(ctx context.Context) (int32, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0, errors.New("no metadata from incoming context")
+	}
+	values := md.Get("user_id")
+	if len(values) != 1 {
+		return 0, errors.New("user_id must be passed once")
+	}
+	userId, err := strconv.Atoi(values[0])
+	if err != nil {
+		return 0, err
+	}
+
+	return int32(userId), nil
+}
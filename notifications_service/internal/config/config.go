@@ -0,0 +1,64 @@
+package config
+
+import (
+	"github.com/caarlos0/env/v8"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/infrastructure/logger"
+	"time"
+)
+
+type Config struct {
+	KafkaConfig
+	NotificationsServiceConfig
+	TracingConfig
+	MetricsConfig
+	HealthConfig
+}
+
+type KafkaConfig struct {
+	Brokers       []string      `env:"KAFKA_BROKERS" envSeparator:"," envDefault:"kafka:19092"`
+	CommentsTopic string        `env:"KAFKA_COMMENTS_TOPIC" envDefault:"comments.topic"`
+	LikesTopic    string        `env:"KAFKA_LIKES_TOPIC" envDefault:"likes.topic"`
+	ConsumerGroup string        `env:"KAFKA_NOTIFICATIONS_CONSUMER_GROUP" envDefault:"notifications-service"`
+	RetryBackoff  time.Duration `env:"KAFKA_NOTIFICATIONS_RETRY_BACKOFF" envDefault:"1s"`
+}
+
+type NotificationsServiceConfig struct {
+	NotificationsServicePort      string `env:"NOTIFICATIONS_SERVICE_PORT" envDefault:":50053"`
+	NotificationsServiceHost      string `env:"NOTIFICATIONS_SERVICE_HOST" envDefault:"notifications-service"`
+	NotificationsPostgresDb       string `env:"NOTIFICATIONS_POSTGRES_DB" envDefault:"notifications_db"`
+	NotificationsPostgresUser     string `env:"NOTIFICATIONS_POSTGRES_USER" envDefault:"username"`
+	NotificationsPostgresPassword string `env:"NOTIFICATIONS_POSTGRES_PASSWORD" envDefault:"password"`
+	NotificationsPostgresPort     string `env:"NOTIFICATIONS_POSTGRES_PORT" envDefault:":5432"`
+	NotificationsPostgresHost     string `env:"NOTIFICATIONS_POSTGRES_HOST" envDefault:"notifications-postgres"`
+}
+
+// TracingConfig selects where spans are exported: "otlp" sends them to TracingOTLPEndpoint,
+// "stdout" prints them and "none" only propagates the trace context.
+type TracingConfig struct {
+	TracingExporter     string  `env:"TRACING_EXPORTER" envDefault:"otlp"`
+	TracingOTLPEndpoint string  `env:"TRACING_OTLP_ENDPOINT" envDefault:"jaeger:4317"`
+	TracingSampleRatio  float64 `env:"TRACING_SAMPLE_RATIO" envDefault:"1"`
+}
+
+// MetricsConfig is the address of the /metrics endpoint, it is served apart from the API.
+type MetricsConfig struct {
+	MetricsPort string `env:"METRICS_PORT" envDefault:":9090"`
+}
+
+// HealthConfig configures the readiness checks, the gRPC health status is refreshed
+// every HealthCheckInterval.
+type HealthConfig struct {
+	HealthCheckInterval time.Duration `env:"HEALTH_CHECK_INTERVAL" envDefault:"10s"`
+	HealthCheckTimeout  time.Duration `env:"HEALTH_CHECK_TIMEOUT" envDefault:"2s"`
+}
+
+func NewConfig() (*Config, error) {
+	cfg := Config{}
+
+	if err := env.Parse(&cfg); err != nil {
+		logger.Logger.Error("error parsing config", "error", err.Error())
+		return nil, err
+	}
+
+	return &cfg, nil
+}
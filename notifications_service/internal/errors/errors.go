@@ -0,0 +1,24 @@
+package errors
+
+type InvalidEventError struct {
+	Reason string
+}
+
+func (err InvalidEventError) Error() string {
+	return "invalid event: " + err.Reason
+}
+
+type UnknownNotificationTypeError struct {
+}
+
+func (err UnknownNotificationTypeError) Error() string {
+	return "Unknown notification type"
+}
+
+type UnknownTracingExporterError struct {
+	Exporter string
+}
+
+func (err UnknownTracingExporterError) Error() string {
+	return "unknown tracing exporter: " + err.Exporter
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/config"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/infrastructure/models"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/opentelemetry-go-extra/otelsql"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+)
+
+func CreateTables(db *bun.DB) error {
+	_, err := db.NewCreateTable().
+		IfNotExists().
+		Model((*models.DbNotification)(nil)).
+		Exec(context.Background())
+	if err != nil {
+		logger.Logger.Error("create notifications table error", "error", err.Error())
+		return err
+	}
+
+	// There is at most one unread notification of a type per post, new events are aggregated into it.
+	_, err = db.NewCreateIndex().
+		IfNotExists().
+		Model((*models.DbNotification)(nil)).
+		Index("notifications_unread_idx").
+		Unique().
+		Column("user_id", "post_id", "type").
+		Where("NOT read").
+		Exec(context.Background())
+	if err != nil {
+		logger.Logger.Error("create notifications index error", "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func InitDb(lc fx.Lifecycle, cfg *config.Config, tp trace.TracerProvider, reg prometheus.Registerer) *bun.DB {
+	dsn := fmt.Sprintf("postgres://%s:%s@%s%s/%s?sslmode=disable",
+		cfg.NotificationsPostgresUser, cfg.NotificationsPostgresPassword, cfg.NotificationsPostgresHost,
+		cfg.NotificationsPostgresPort, cfg.NotificationsPostgresDb)
+
+	sqldb, err := otelsql.Open("pgx", dsn, otelsql.WithTracerProvider(tp), otelsql.WithDBSystem("postgresql"))
+	if err != nil {
+		logger.Logger.Error("open database error", "error", err.Error())
+		return nil
+	}
+
+	reg.MustRegister(collectors.NewDBStatsCollector(sqldb, cfg.NotificationsPostgresDb))
+
+	db := bun.NewDB(sqldb, pgdialect.New())
+	err = CreateTables(db)
+	if err != nil {
+		logger.Logger.Error("create tables", "error", err.Error())
+		return nil
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return db.Close()
+		},
+	})
+
+	return db
+}
@@ -0,0 +1,59 @@
+package health
+
+import (
+	"context"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/config"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/infrastructure/logger"
+	pb "github.com/grigorovskiiy/soa-hse/protos"
+	"go.uber.org/fx"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"time"
+)
+
+// RunHealthServer refreshes the serving status of the server and of pb.NotificationsService
+// every HealthCheckInterval. The status is NOT_SERVING until the first checks pass.
+func RunHealthServer(lc fx.Lifecycle, hs *grpchealth.Server, checker *Checker, cfg *config.Config) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	update := func() {
+		status := healthpb.HealthCheckResponse_SERVING
+		report, ok := checker.Ready(ctx)
+		if !ok {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+			logger.Logger.Warn("readiness checks failed", "checks", report.Checks)
+		}
+		hs.SetServingStatus("", status)
+		hs.SetServingStatus(pb.NotificationsService_ServiceDesc.ServiceName, status)
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			hs.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+			hs.SetServingStatus(pb.NotificationsService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(cfg.HealthCheckInterval)
+				defer ticker.Stop()
+
+				for {
+					update()
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			<-done
+			hs.Shutdown()
+			return nil
+		},
+	})
+}
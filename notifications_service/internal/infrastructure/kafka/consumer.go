@@ -0,0 +1,193 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/config"
+	notificationsErrors "github.com/grigorovskiiy/soa-hse/notifications_service/internal/errors"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/infrastructure/models"
+	pb "github.com/grigorovskiiy/soa-hse/protos"
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"google.golang.org/protobuf/proto"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	contentTypeHeader   = "content-type"
+	contentTypeProtobuf = "application/x-protobuf"
+	eventVersion        = 2
+)
+
+type EventsHandler interface {
+	HandleEvent(ctx context.Context, notificationType string, e *models.Event) error
+}
+
+type Consumer struct {
+	readers map[string]*kafka.Reader
+	handler EventsHandler
+	cfg     *config.Config
+	tracer  trace.Tracer
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func NewConsumer(cfg *config.Config, handler EventsHandler, tp trace.TracerProvider) *Consumer {
+	topics := map[string]string{
+		models.LikeNotification:    cfg.LikesTopic,
+		models.CommentNotification: cfg.CommentsTopic,
+	}
+
+	readers := make(map[string]*kafka.Reader, len(topics))
+	for notificationType, topic := range topics {
+		readers[notificationType] = kafka.NewReader(kafka.ReaderConfig{
+			Brokers:  cfg.Brokers,
+			GroupID:  cfg.ConsumerGroup,
+			Topic:    topic,
+			MinBytes: 1,
+			MaxBytes: 10e6,
+		})
+	}
+
+	return &Consumer{
+		readers: readers,
+		handler: handler,
+		cfg:     cfg,
+		tracer:  tp.Tracer("kafka"),
+	}
+}
+
+func RunConsumer(lc fx.Lifecycle, c *Consumer) error {
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			ctx, cancel := context.WithCancel(context.Background())
+			c.cancel = cancel
+			for notificationType, reader := range c.readers {
+				c.wg.Add(1)
+				go func() {
+					defer c.wg.Done()
+					c.consume(ctx, notificationType, reader)
+				}()
+			}
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			c.cancel()
+			c.wg.Wait()
+
+			var errs []error
+			for _, reader := range c.readers {
+				errs = append(errs, reader.Close())
+			}
+
+			return errors.Join(errs...)
+		},
+	})
+
+	return nil
+}
+
+func (c *Consumer) consume(ctx context.Context, notificationType string, reader *kafka.Reader) {
+	logger := logger.Logger.With("topic", reader.Config().Topic, "type", notificationType)
+	logger.Info("kafka consumer started")
+
+	for ctx.Err() == nil {
+		msg, err := reader.FetchMessage(ctx)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				logger.Error("kafka fetch message error", "error", err.Error())
+			}
+			continue
+		}
+
+		event, err := decodeEvent(msg)
+		if err != nil {
+			// Invalid events can not become valid, they are skipped.
+			logger.Error("invalid kafka event", "error", err.Error(), "partition", msg.Partition, "offset", msg.Offset,
+				"request_id", requestID(&msg))
+		} else {
+			msgCtx, span := startMessageSpan(ctx, c.tracer, &msg)
+			c.retry(ctx, logger.With("request_id", requestID(&msg)), "handle event", func() error { return c.handler.HandleEvent(msgCtx, notificationType, event) })
+			span.End()
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		c.retry(ctx, logger, "commit message", func() error { return reader.CommitMessages(ctx, msg) })
+	}
+
+	logger.Info("kafka consumer stopped")
+}
+
+// retry repeats f until it succeeds or the consumer is stopped.
+func (c *Consumer) retry(ctx context.Context, logger *slog.Logger, op string, f func() error) {
+	for {
+		err := f()
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+
+		logger.Error("kafka consumer "+op+" error", "error", err.Error())
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.cfg.RetryBackoff):
+		}
+	}
+}
+
+// decodeEvent supports both the protobuf envelope (version 2) and the plain JSON payload (version 1).
+func decodeEvent(msg kafka.Message) (*models.Event, error) {
+	var event models.Event
+	if header(msg, contentTypeHeader) == contentTypeProtobuf {
+		var env pb.EventEnvelope
+		if err := proto.Unmarshal(msg.Value, &env); err != nil {
+			return nil, notificationsErrors.InvalidEventError{Reason: err.Error()}
+		}
+		if env.Version != eventVersion {
+			return nil, notificationsErrors.InvalidEventError{Reason: "unsupported event version " + strconv.Itoa(int(env.Version))}
+		}
+
+		interaction := env.GetInteraction()
+		if interaction == nil {
+			return nil, notificationsErrors.InvalidEventError{Reason: "unexpected payload of event " + env.Type.String()}
+		}
+		event = models.Event{
+			UserId:   int(interaction.UserId),
+			PostId:   int(interaction.PostId),
+			AuthorId: int(interaction.AuthorId),
+			Undo:     env.Type == pb.EventType_POST_UNLIKED,
+		}
+	} else if err := json.Unmarshal(msg.Value, &event); err != nil {
+		return nil, notificationsErrors.InvalidEventError{Reason: err.Error()}
+	}
+
+	switch {
+	case event.PostId <= 0:
+		return nil, notificationsErrors.InvalidEventError{Reason: "post_id is empty"}
+	case event.UserId <= 0:
+		return nil, notificationsErrors.InvalidEventError{Reason: "user_id is empty"}
+	case event.AuthorId <= 0:
+		return nil, notificationsErrors.InvalidEventError{Reason: "author_id is empty"}
+	}
+
+	return &event, nil
+}
+
+func header(msg kafka.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,63 @@
+package kafka
+
+import (
+	"context"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/infrastructure/logger"
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// requestID is the ID of the request that caused the event, set by the producer.
+func requestID(msg *kafka.Message) string {
+	return headerCarrier{msg: msg}.Get(logger.RequestIDKey)
+}
+
+// headerCarrier lets the propagator write and read the trace context in kafka message headers.
+type headerCarrier struct {
+	msg *kafka.Message
+}
+
+func (c headerCarrier) Get(key string) string {
+	for _, h := range c.msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+
+	return ""
+}
+
+func (c headerCarrier) Set(key, value string) {
+	for i, h := range c.msg.Headers {
+		if h.Key == key {
+			c.msg.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+
+	c.msg.Headers = append(c.msg.Headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, len(c.msg.Headers))
+	for i, h := range c.msg.Headers {
+		keys[i] = h.Key
+	}
+
+	return keys
+}
+
+// startMessageSpan continues the trace of the message in the span of its handling, the
+// context also carries the request ID of the message for the logs of the handler.
+func startMessageSpan(ctx context.Context, tracer trace.Tracer, msg *kafka.Message) (context.Context, trace.Span) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier{msg: msg})
+	if id := requestID(msg); id != "" {
+		ctx = logger.WithRequestID(ctx, id)
+	}
+
+	return tracer.Start(ctx, msg.Topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(semconv.MessagingSystemKafka, semconv.MessagingDestinationName(msg.Topic)))
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"github.com/uptrace/bun"
+	"time"
+)
+
+const (
+	LikeNotification    = "like"
+	CommentNotification = "comment"
+)
+
+// DbNotification groups events of one type on one post. While it is unread every new event
+// adds its actor to it instead of creating another notification.
+type DbNotification struct {
+	bun.BaseModel `bun:"table:notifications,alias:notification"`
+	Id            int       `bun:"id,pk,autoincrement" json:"id"`
+	UserId        int       `bun:"user_id,notnull" json:"user_id"`
+	PostId        int       `bun:"post_id,notnull" json:"post_id"`
+	Type          string    `bun:"type,notnull" json:"type"`
+	ActorIds      []int     `bun:"actor_ids,array" json:"actor_ids"`
+	Read          bool      `bun:"read,notnull,default:false" json:"read"`
+	CreatedAt     time.Time `bun:"created_at" json:"created_at"`
+	UpdatedAt     time.Time `bun:"updated_at" json:"updated_at"`
+}
+
+// Event is a like or a comment of UserId on a post of AuthorId. Undo is set for an unlike.
+type Event struct {
+	UserId   int  `json:"user_id"`
+	PostId   int  `json:"post_id"`
+	AuthorId int  `json:"author_id"`
+	Undo     bool `json:"-"`
+}
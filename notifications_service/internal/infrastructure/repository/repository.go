@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/infrastructure/models"
+	"github.com/uptrace/bun"
+)
+
+type NRepository struct {
+	db *bun.DB
+}
+
+func NewNRepository(db *bun.DB) *NRepository {
+	return &NRepository{db: db}
+}
+
+// AddNotification creates an unread notification or adds the actor to the unread one of the
+// same type on the same post. Actors are stored once, so redelivered events change nothing.
+func (r *NRepository) AddNotification(ctx context.Context, n *models.DbNotification) error {
+	_, err := r.db.NewInsert().
+		Model(n).
+		On("CONFLICT (user_id, post_id, type) WHERE NOT read DO UPDATE").
+		Set(`actor_ids = CASE WHEN EXCLUDED.actor_ids <@ notification.actor_ids 
+				THEN notification.actor_ids ELSE notification.actor_ids || EXCLUDED.actor_ids END`).
+		Set("updated_at = EXCLUDED.updated_at").
+		Exec(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("execing add notification db error", "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// RemoveActor takes the actor back from the unread notification of the type of n on the post
+// of n and deletes the notification once no actors are left. Read notifications are kept.
+func (r *NRepository) RemoveActor(ctx context.Context, n *models.DbNotification, actorID int) error {
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().
+			Model((*models.DbNotification)(nil)).
+			Set("actor_ids = array_remove(actor_ids, ?)", actorID).
+			Where("user_id = ?", n.UserId).
+			Where("post_id = ?", n.PostId).
+			Where("type = ?", n.Type).
+			Where("NOT read").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewDelete().
+			Model((*models.DbNotification)(nil)).
+			Where("user_id = ?", n.UserId).
+			Where("post_id = ?", n.PostId).
+			Where("type = ?", n.Type).
+			Where("NOT read").
+			Where("cardinality(actor_ids) = 0").
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("execing remove notification actor db error", "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (r *NRepository) GetNotifications(ctx context.Context, userID int32, page int32, limit int32) ([]*models.DbNotification, error) {
+	var notifications []*models.DbNotification
+	offset := (page - 1) * limit
+	err := r.db.NewSelect().
+		Model(&notifications).
+		Where("user_id = ?", userID).
+		Order("updated_at DESC").
+		Limit(int(limit)).
+		Offset(int(offset)).
+		Scan(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("scan get notifications error", "error", err.Error())
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// MarkRead marks the given notifications of the user as read, or all of them if all is set.
+func (r *NRepository) MarkRead(ctx context.Context, userID int32, ids []int32, all bool) error {
+	query := r.db.NewUpdate().
+		Model((*models.DbNotification)(nil)).
+		Set("read = true").
+		Where("user_id = ?", userID).
+		Where("NOT read")
+	if !all {
+		query = query.Where("id IN (?)", bun.In(ids))
+	}
+
+	if _, err := query.Exec(ctx); err != nil {
+		logger.FromContext(ctx).Error("execing mark notifications read db error", "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (r *NRepository) GetUnreadCount(ctx context.Context, userID int32) (int, error) {
+	count, err := r.db.NewSelect().
+		Model((*models.DbNotification)(nil)).
+		Where("user_id = ?", userID).
+		Where("NOT read").
+		Count(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("get unread notifications count db error", "error", err.Error())
+		return 0, err
+	}
+
+	return count, nil
+}
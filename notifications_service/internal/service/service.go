@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/errors"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/notifications_service/internal/infrastructure/models"
+	pb "github.com/grigorovskiiy/soa-hse/protos"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+type NotificationsRepository interface {
+	AddNotification(context.Context, *models.DbNotification) error
+	RemoveActor(context.Context, *models.DbNotification, int) error
+	GetNotifications(context.Context, int32, int32, int32) ([]*models.DbNotification, error)
+	MarkRead(context.Context, int32, []int32, bool) error
+	GetUnreadCount(context.Context, int32) (int, error)
+}
+
+const (
+	defaultPage     = 1
+	defaultPageSize = 10
+)
+
+type Service struct {
+	repository NotificationsRepository
+}
+
+func NewService(repository NotificationsRepository) *Service {
+	return &Service{repository: repository}
+}
+
+// HandleEvent notifies the author of the post about a like or a comment, an unlike takes its
+// actor back from the unread notification. Authors are not notified about their own actions.
+func (s *Service) HandleEvent(ctx context.Context, notificationType string, e *models.Event) error {
+	if e.UserId == e.AuthorId {
+		return nil
+	}
+
+	now := time.Now()
+	notification := models.DbNotification{
+		UserId:    e.AuthorId,
+		PostId:    e.PostId,
+		Type:      notificationType,
+		ActorIds:  []int{e.UserId},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if e.Undo {
+		if err := s.repository.RemoveActor(ctx, &notification, e.UserId); err != nil {
+			logger.FromContext(ctx).Error("remove notification actor error", "error", err.Error())
+			return err
+		}
+		return nil
+	}
+
+	if err := s.repository.AddNotification(ctx, &notification); err != nil {
+		logger.FromContext(ctx).Error("add notification error", "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *Service) GetNotifications(ctx context.Context, p *pb.PaginatedListRequest, userID int32) (*pb.ListNotificationsResponse, error) {
+	page, pageSize := p.Page, p.PageSize
+	if page < 1 {
+		page = defaultPage
+	}
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+
+	notifications, err := s.repository.GetNotifications(ctx, userID, page, pageSize)
+	if err != nil {
+		logger.FromContext(ctx).Error("get notifications error", "error", err.Error())
+		return nil, err
+	}
+
+	var res pb.ListNotificationsResponse
+	for _, n := range notifications {
+		text, err := notificationText(n)
+		if err != nil {
+			logger.FromContext(ctx).Error("notification text error", "error", err.Error(), "type", n.Type)
+			return nil, err
+		}
+
+		actorIDs := make([]int32, 0, len(n.ActorIds))
+		for _, id := range n.ActorIds {
+			actorIDs = append(actorIDs, int32(id))
+		}
+
+		res.Notifications = append(res.Notifications, &pb.NotificationResponse{
+			NotificationId: int32(n.Id),
+			Type:           n.Type,
+			PostId:         int32(n.PostId),
+			ActorsCount:    int32(len(n.ActorIds)),
+			ActorIds:       actorIDs,
+			Text:           text,
+			Read:           n.Read,
+			CreatedAt:      timestamppb.New(n.CreatedAt),
+			UpdatedAt:      timestamppb.New(n.UpdatedAt),
+		})
+	}
+
+	return &res, nil
+}
+
+func (s *Service) MarkNotificationsRead(ctx context.Context, p *pb.MarkNotificationsReadRequest, userID int32) error {
+	if !p.All && len(p.NotificationIds) == 0 {
+		return nil
+	}
+
+	if err := s.repository.MarkRead(ctx, userID, p.NotificationIds, p.All); err != nil {
+		logger.FromContext(ctx).Error("mark notifications read error", "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (s *Service) GetUnreadNotificationsCount(ctx context.Context, userID int32) (*pb.CountResponse, error) {
+	count, err := s.repository.GetUnreadCount(ctx, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error("get unread notifications count error", "error", err.Error())
+		return nil, err
+	}
+
+	return &pb.CountResponse{Count: int32(count)}, nil
+}
+
+// notificationText renders aggregated notifications, e.g. "5 people liked your post".
+func notificationText(n *models.DbNotification) (string, error) {
+	who := "1 person"
+	if len(n.ActorIds) != 1 {
+		who = fmt.Sprintf("%d people", len(n.ActorIds))
+	}
+
+	switch n.Type {
+	case models.LikeNotification:
+		return who + " liked your post", nil
+	case models.CommentNotification:
+		return who + " commented on your post", nil
+	default:
+		return "", errors.UnknownNotificationTypeError{}
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/application"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/config"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/db"
+	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/health"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/kafka"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/metrics"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/repository"
+	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/tracing"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/server"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/service/eventsservice"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/service/postsservice"
@@ -23,6 +26,9 @@ func init() {
 func main() {
 	addOpts := fx.Options(
 		fx.Provide(config.NewConfig),
+		fx.Provide(tracing.NewTracerProvider),
+		metrics.Module,
+		health.Module,
 		fx.Provide(db.InitDb),
 		fx.Provide(repository.NewPRepository),
 		fx.Provide(func(r *repository.PRepository) postsservice.PostsRepository {
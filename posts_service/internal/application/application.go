@@ -5,28 +5,40 @@ import (
 	"errors"
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/config"
+	postsErrors "github.com/grigorovskiiy/soa-hse/posts_service/internal/errors"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/logger"
-	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/models"
+	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/metrics"
 	pb "github.com/grigorovskiiy/soa-hse/protos"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"strconv"
-	"time"
 )
 
 type PostsService interface {
-	CreatePost(context.Context, *pb.PostDataRequest, int32) error
+	CreatePost(context.Context, *pb.PostDataRequest, int32) (*pb.PostDataResponse, error)
 	DeletePost(context.Context, *pb.PostID, int32) error
-	UpdatePost(context.Context, *pb.UpdatePostRequest, int32) error
+	UpdatePost(context.Context, *pb.UpdatePostRequest, int32) (*pb.PostDataResponse, error)
 	GetPost(context.Context, *pb.PostID, int32) (*pb.PostDataResponse, error)
+	GetPostAuthor(context.Context, int32) (int32, error)
+	GetPostMeta(context.Context, *pb.PostID) (*pb.PostMetaResponse, error)
+	GetPostsMeta(context.Context, *pb.BatchPostsMetaRequest) (*pb.BatchPostsMetaResponse, error)
 	GetPostList(context.Context, *pb.PaginatedListRequest, int32) (*pb.ListPostsResponse, error)
-	PostComment(context.Context, *pb.PostCommentRequest, int32) error
-	PostLike(context.Context, *pb.PostID, int32) error
-	PostView(context.Context, *pb.PostID, int32) error
+	PostComment(context.Context, *pb.PostCommentRequest, int32) (int32, error)
+	PostLike(context.Context, *pb.PostID, int32) (int32, bool, error)
+	DeletePostLike(context.Context, *pb.PostID, int32) error
+	PostView(context.Context, *pb.PostID, int32) (int32, error)
 	GetCommentList(context.Context, *pb.PaginatedListRequest, int32) (*pb.ListCommentsResponse, error)
 }
 
 type EventsService interface {
-	SendEvent(context.Context, string, any) error
+	PublishPostViewed(context.Context, string, *pb.InteractionEvent) error
+	PublishPostLiked(context.Context, string, *pb.InteractionEvent) error
+	PublishPostUnliked(context.Context, string, *pb.InteractionEvent) error
+	PublishPostCommented(context.Context, string, *pb.InteractionEvent) error
+	PublishPostCreated(context.Context, string, *pb.PostEvent) error
+	PublishPostUpdated(context.Context, string, *pb.PostEvent) error
+	PublishPostDeleted(context.Context, string, *pb.PostDeletedEvent) error
 }
 
 type PostsServiceApp struct {
@@ -34,14 +46,34 @@ type PostsServiceApp struct {
 	PostsService  PostsService
 	EventsService EventsService
 	cfg           *config.Config
+	metrics       *metrics.PostsMetrics
 }
 
-func NewPostsApp(pS PostsService, eS EventsService, cfg *config.Config) *PostsServiceApp {
-	return &PostsServiceApp{PostsService: pS, EventsService: eS, cfg: cfg}
+func NewPostsApp(pS PostsService, eS EventsService, cfg *config.Config, m *metrics.PostsMetrics) *PostsServiceApp {
+	return &PostsServiceApp{PostsService: pS, EventsService: eS, cfg: cfg, metrics: m}
+}
+
+func newInteractionEvent(userID, postID, authorID int32) *pb.InteractionEvent {
+	return &pb.InteractionEvent{UserId: userID, PostId: postID, AuthorId: authorID}
+}
+
+func newPostEvent(post *pb.PostDataResponse) *pb.PostEvent {
+	return &pb.PostEvent{
+		PostId:       post.PostId,
+		AuthorId:     post.UserId,
+		Name:         post.PostName,
+		Description:  post.PostDescription,
+		SecurityFlag: post.SecurityFlag,
+		Tags:         post.Tags,
+	}
+}
+
+func newPostDeletedEvent(postID, authorID int32) *pb.PostDeletedEvent {
+	return &pb.PostDeletedEvent{PostId: postID, AuthorId: authorID}
 }
 
 func (s *PostsServiceApp) CreatePost(ctx context.Context, pb *pb.PostDataRequest) (*empty.Empty, error) {
-	logger := logger.Logger.With("method", "CreatePost")
+	logger := logger.FromContext(ctx).With("method", "CreatePost")
 	logger.Info("posts grpc request started")
 	userID, err := GetUserID(ctx)
 	if err != nil {
@@ -49,10 +81,17 @@ func (s *PostsServiceApp) CreatePost(ctx context.Context, pb *pb.PostDataRequest
 		return nil, err
 	}
 
-	if err = s.PostsService.CreatePost(ctx, pb, userID); err != nil {
+	post, err := s.PostsService.CreatePost(ctx, pb, userID)
+	if err != nil {
 		logger.Error("create post error", "error", err.Error())
 		return nil, err
 	}
+	s.metrics.PostsCreated.Inc()
+
+	if err = s.EventsService.PublishPostCreated(ctx, s.cfg.PostsTopic, newPostEvent(post)); err != nil {
+		logger.Error("create post send event error", "error", err.Error())
+		return nil, err
+	}
 
 	logger.Info("posts grpc request completed")
 
@@ -60,7 +99,7 @@ func (s *PostsServiceApp) CreatePost(ctx context.Context, pb *pb.PostDataRequest
 }
 
 func (s *PostsServiceApp) DeletePost(ctx context.Context, pb *pb.PostID) (*empty.Empty, error) {
-	logger := logger.Logger.With("method", "DeletePost")
+	logger := logger.FromContext(ctx).With("method", "DeletePost")
 	logger.Info("posts grpc request started")
 	userID, err := GetUserID(ctx)
 	if err != nil {
@@ -73,12 +112,17 @@ func (s *PostsServiceApp) DeletePost(ctx context.Context, pb *pb.PostID) (*empty
 		return nil, err
 	}
 
+	if err = s.EventsService.PublishPostDeleted(ctx, s.cfg.PostsTopic, newPostDeletedEvent(pb.PostId, userID)); err != nil {
+		logger.Error("delete post send event error", "error", err.Error())
+		return nil, err
+	}
+
 	logger.Info("posts grpc request completed")
 	return &empty.Empty{}, nil
 }
 
 func (s *PostsServiceApp) UpdatePost(ctx context.Context, pb *pb.UpdatePostRequest) (*empty.Empty, error) {
-	logger := logger.Logger.With("method", "UpdatePost")
+	logger := logger.FromContext(ctx).With("method", "UpdatePost")
 	logger.Info("posts grpc request started")
 	userID, err := GetUserID(ctx)
 	if err != nil {
@@ -86,8 +130,17 @@ func (s *PostsServiceApp) UpdatePost(ctx context.Context, pb *pb.UpdatePostReque
 		return nil, err
 	}
 
-	if err = s.PostsService.UpdatePost(ctx, pb, userID); err != nil {
+	post, err := s.PostsService.UpdatePost(ctx, pb, userID)
+	if err != nil {
 		logger.Error("update post error", "error", err.Error())
+		if errors.As(err, &postsErrors.InvalidUpdateMaskError{}) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+
+	if err = s.EventsService.PublishPostUpdated(ctx, s.cfg.PostsTopic, newPostEvent(post)); err != nil {
+		logger.Error("update post send event error", "error", err.Error())
 		return nil, err
 	}
 
@@ -96,7 +149,7 @@ func (s *PostsServiceApp) UpdatePost(ctx context.Context, pb *pb.UpdatePostReque
 }
 
 func (s *PostsServiceApp) GetPost(ctx context.Context, pb *pb.PostID) (*pb.PostDataResponse, error) {
-	logger := logger.Logger.With("method", "GetPost")
+	logger := logger.FromContext(ctx).With("method", "GetPost")
 	logger.Info("posts grpc request started")
 
 	userID, err := GetUserID(ctx)
@@ -116,7 +169,7 @@ func (s *PostsServiceApp) GetPost(ctx context.Context, pb *pb.PostID) (*pb.PostD
 }
 
 func (s *PostsServiceApp) GetPostList(ctx context.Context, pb *pb.PaginatedListRequest) (*pb.ListPostsResponse, error) {
-	logger := logger.Logger.With("method", "GetPostList")
+	logger := logger.FromContext(ctx).With("method", "GetPostList")
 	logger.Info("posts grpc request started")
 
 	userID, err := GetUserID(ctx)
@@ -135,7 +188,7 @@ func (s *PostsServiceApp) GetPostList(ctx context.Context, pb *pb.PaginatedListR
 }
 
 func (s *PostsServiceApp) PostComment(ctx context.Context, pb *pb.PostCommentRequest) (*empty.Empty, error) {
-	logger := logger.Logger.With("method", "PostComment")
+	logger := logger.FromContext(ctx).With("method", "PostComment")
 	logger.Info("posts grpc request started")
 
 	userID, err := GetUserID(ctx)
@@ -144,12 +197,14 @@ func (s *PostsServiceApp) PostComment(ctx context.Context, pb *pb.PostCommentReq
 		return nil, err
 	}
 
-	if err = s.PostsService.PostComment(ctx, pb, userID); err != nil {
+	authorID, err := s.PostsService.PostComment(ctx, pb, userID)
+	if err != nil {
 		logger.Error("post comment error", "error", err.Error())
 		return nil, err
 	}
+	s.metrics.Comments.Inc()
 
-	if err = s.EventsService.SendEvent(ctx, s.cfg.CommentsTopic, models.LikeViewCommentUpdate{PostId: int(pb.PostId), UserId: int(userID), Time: time.Now()}); err != nil {
+	if err = s.EventsService.PublishPostCommented(ctx, s.cfg.CommentsTopic, newInteractionEvent(userID, pb.PostId, authorID)); err != nil {
 		logger.Error("post comment send event error", "error", err.Error())
 		return nil, err
 	}
@@ -159,7 +214,7 @@ func (s *PostsServiceApp) PostComment(ctx context.Context, pb *pb.PostCommentReq
 }
 
 func (s *PostsServiceApp) PostLike(ctx context.Context, pb *pb.PostID) (*empty.Empty, error) {
-	logger := logger.Logger.With("method", "PostLike")
+	logger := logger.FromContext(ctx).With("method", "PostLike")
 	logger.Info("posts grpc request started")
 	userID, err := GetUserID(ctx)
 	if err != nil {
@@ -167,12 +222,18 @@ func (s *PostsServiceApp) PostLike(ctx context.Context, pb *pb.PostID) (*empty.E
 		return nil, err
 	}
 
-	if err = s.PostsService.PostLike(ctx, pb, userID); err != nil {
+	authorID, liked, err := s.PostsService.PostLike(ctx, pb, userID)
+	if err != nil {
 		logger.Error("post like error", "error", err.Error())
 		return nil, err
 	}
+	if !liked {
+		logger.Info("post already liked, posts grpc request completed")
+		return &empty.Empty{}, nil
+	}
+	s.metrics.Likes.Inc()
 
-	if err = s.EventsService.SendEvent(ctx, s.cfg.LikesTopic, models.LikeViewCommentUpdate{PostId: int(pb.PostId), UserId: int(userID), Time: time.Now()}); err != nil {
+	if err = s.EventsService.PublishPostLiked(ctx, s.cfg.LikesTopic, newInteractionEvent(userID, pb.PostId, authorID)); err != nil {
 		logger.Error("post like send event error", "error", err.Error())
 		return nil, err
 
@@ -182,8 +243,40 @@ func (s *PostsServiceApp) PostLike(ctx context.Context, pb *pb.PostID) (*empty.E
 	return &empty.Empty{}, nil
 }
 
+func (s *PostsServiceApp) DeletePostLike(ctx context.Context, pb *pb.PostID) (*empty.Empty, error) {
+	logger := logger.FromContext(ctx).With("method", "DeletePostLike")
+	logger.Info("posts grpc request started")
+	userID, err := GetUserID(ctx)
+	if err != nil {
+		logger.Error("error getting userID from ctx", "error", err.Error())
+		return nil, err
+	}
+
+	if err = s.PostsService.DeletePostLike(ctx, pb, userID); err != nil {
+		logger.Error("delete post like error", "error", err.Error())
+		if errors.As(err, &postsErrors.LikeNotFoundError{}) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+
+	authorID, err := s.PostsService.GetPostAuthor(ctx, pb.PostId)
+	if err != nil {
+		logger.Error("delete post like get author error", "error", err.Error())
+		return nil, err
+	}
+
+	if err = s.EventsService.PublishPostUnliked(ctx, s.cfg.LikesTopic, newInteractionEvent(userID, pb.PostId, authorID)); err != nil {
+		logger.Error("delete post like send event error", "error", err.Error())
+		return nil, err
+	}
+
+	logger.Info("posts grpc request completed")
+	return &empty.Empty{}, nil
+}
+
 func (s *PostsServiceApp) PostView(ctx context.Context, pb *pb.PostID) (*empty.Empty, error) {
-	logger := logger.Logger.With("method", "PostView")
+	logger := logger.FromContext(ctx).With("method", "PostView")
 	logger.Info("posts grpc request started")
 	userID, err := GetUserID(ctx)
 	if err != nil {
@@ -191,12 +284,14 @@ func (s *PostsServiceApp) PostView(ctx context.Context, pb *pb.PostID) (*empty.E
 		return nil, err
 	}
 
-	if err = s.PostsService.PostView(ctx, pb, userID); err != nil {
+	authorID, err := s.PostsService.PostView(ctx, pb, userID)
+	if err != nil {
 		logger.Error("post view error", "error", err.Error())
 		return nil, err
 	}
+	s.metrics.Views.Inc()
 
-	if err = s.EventsService.SendEvent(ctx, s.cfg.ViewsTopic, models.LikeViewCommentUpdate{PostId: int(pb.PostId), UserId: int(userID), Time: time.Now()}); err != nil {
+	if err = s.EventsService.PublishPostViewed(ctx, s.cfg.ViewsTopic, newInteractionEvent(userID, pb.PostId, authorID)); err != nil {
 		logger.Error("post view send event error", "error", err.Error())
 		return nil, err
 	}
@@ -206,7 +301,7 @@ func (s *PostsServiceApp) PostView(ctx context.Context, pb *pb.PostID) (*empty.E
 }
 
 func (s *PostsServiceApp) GetCommentList(ctx context.Context, pb *pb.PaginatedListRequest) (*pb.ListCommentsResponse, error) {
-	logger := logger.Logger.With("method", "GetCommentsList")
+	logger := logger.FromContext(ctx).With("method", "GetCommentsList")
 	logger.Info("posts grpc request started")
 	userID, err := GetUserID(ctx)
 	if err != nil {
@@ -224,13 +319,47 @@ func (s *PostsServiceApp) GetCommentList(ctx context.Context, pb *pb.PaginatedLi
 	return comments, nil
 }
 
+func (s *PostsServiceApp) GetPostMeta(ctx context.Context, pb *pb.PostID) (*pb.PostMetaResponse, error) {
+	logger := logger.FromContext(ctx).With("method", "GetPostMeta")
+	logger.Info("posts grpc request started")
+
+	meta, err := s.PostsService.GetPostMeta(ctx, pb)
+	if err != nil {
+		logger.Error("get post meta error", "error", err.Error())
+		if errors.As(err, &postsErrors.PostNotFoundError{}) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+
+	logger.Info("posts grpc request completed")
+	return meta, nil
+}
+
+func (s *PostsServiceApp) GetPostsMeta(ctx context.Context, pb *pb.BatchPostsMetaRequest) (*pb.BatchPostsMetaResponse, error) {
+	logger := logger.FromContext(ctx).With("method", "GetPostsMeta")
+	logger.Info("posts grpc request started")
+
+	meta, err := s.PostsService.GetPostsMeta(ctx, pb)
+	if err != nil {
+		logger.Error("get posts meta error", "error", err.Error())
+		return nil, err
+	}
+
+	logger.Info("posts grpc request completed")
+	return meta, nil
+}
+
 func GetUserID(ctx context.Context) (int32, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		return 0, errors.New("no metadata from incoming context")
 	}
-	m := md.Get("user_id")[0]
-	userId, err := strconv.Atoi(m)
+	values := md.Get("user_id")
+	if len(values) != 1 {
+		return 0, errors.New("user_id must be passed once")
+	}
+	userId, err := strconv.Atoi(values[0])
 	if err != nil {
 		return 0, err
 	}
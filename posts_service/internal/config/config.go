@@ -3,18 +3,52 @@ package config
 import (
 	"github.com/caarlos0/env/v8"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/logger"
+	"time"
 )
 
 type Config struct {
 	KafkaConfig
 	PostsServiceConfig
+	TracingConfig
+	MetricsConfig
+	HealthConfig
 }
 
 type KafkaConfig struct {
+	ProducerConfig
 	Brokers       []string `env:"KAFKA_BROKERS" envSeparator:"," envDefault:"kafka:19092"`
 	CommentsTopic string   `env:"KAFKA_COMMENTS_TOPIC" envDefault:"comments.topic"`
 	LikesTopic    string   `env:"KAFKA_LIKES_TOPIC" envDefault:"likes.topic"`
 	ViewsTopic    string   `env:"KAFKA_VIEWS_TOPIC" envDefault:"views.topic"`
+	PostsTopic    string   `env:"KAFKA_POSTS_TOPIC" envDefault:"posts.topic"`
+
+	CommentsTopicSpec TopicSpec `envPrefix:"KAFKA_COMMENTS_TOPIC_"`
+	LikesTopicSpec    TopicSpec `envPrefix:"KAFKA_LIKES_TOPIC_"`
+	ViewsTopicSpec    TopicSpec `envPrefix:"KAFKA_VIEWS_TOPIC_"`
+	PostsTopicSpec    TopicSpec `envPrefix:"KAFKA_POSTS_TOPIC_"`
+}
+
+// ProducerConfig configures BaseProducer. RequiredAcks is -1 for all replicas, 1 for the leader
+// and 0 for none.
+type ProducerConfig struct {
+	ProducerAsync          bool          `env:"KAFKA_PRODUCER_ASYNC" envDefault:"true"`
+	ProducerRequiredAcks   int           `env:"KAFKA_PRODUCER_REQUIRED_ACKS" envDefault:"-1"`
+	ProducerBatchSize      int           `env:"KAFKA_PRODUCER_BATCH_SIZE" envDefault:"100"`
+	ProducerBatchTimeout   time.Duration `env:"KAFKA_PRODUCER_BATCH_TIMEOUT" envDefault:"10ms"`
+	ProducerBufferSize     int           `env:"KAFKA_PRODUCER_BUFFER_SIZE" envDefault:"10000"`
+	ProducerEnqueueTimeout time.Duration `env:"KAFKA_PRODUCER_ENQUEUE_TIMEOUT" envDefault:"100ms"`
+	ProducerMaxRetries     int           `env:"KAFKA_PRODUCER_MAX_RETRIES" envDefault:"5"`
+	ProducerRetryBackoff   time.Duration `env:"KAFKA_PRODUCER_RETRY_BACKOFF" envDefault:"100ms"`
+}
+
+// TopicSpec describes how a topic is created and kept in sync on startup.
+// Retention and CleanupPolicy are applied only when they are set, the topic keeps the broker
+// defaults or the configs set by an operator otherwise. A negative retention keeps messages forever.
+type TopicSpec struct {
+	Partitions        int           `env:"PARTITIONS" envDefault:"3"`
+	ReplicationFactor int           `env:"REPLICATION_FACTOR" envDefault:"1"`
+	Retention         time.Duration `env:"RETENTION"`
+	CleanupPolicy     string        `env:"CLEANUP_POLICY"`
 }
 
 type PostsServiceConfig struct {
@@ -27,6 +61,26 @@ type PostsServiceConfig struct {
 	PostsPostgresHost     string `env:"POSTS_POSTGRES_HOST" envDefault:"posts-postgres"`
 }
 
+// TracingConfig selects where spans are exported: "otlp" sends them to TracingOTLPEndpoint,
+// "stdout" prints them and "none" only propagates the trace context.
+type TracingConfig struct {
+	TracingExporter     string  `env:"TRACING_EXPORTER" envDefault:"otlp"`
+	TracingOTLPEndpoint string  `env:"TRACING_OTLP_ENDPOINT" envDefault:"jaeger:4317"`
+	TracingSampleRatio  float64 `env:"TRACING_SAMPLE_RATIO" envDefault:"1"`
+}
+
+// MetricsConfig is the address of the /metrics endpoint, it is served apart from the API.
+type MetricsConfig struct {
+	MetricsPort string `env:"METRICS_PORT" envDefault:":9090"`
+}
+
+// HealthConfig configures the readiness checks, the gRPC health status is refreshed
+// every HealthCheckInterval.
+type HealthConfig struct {
+	HealthCheckInterval time.Duration `env:"HEALTH_CHECK_INTERVAL" envDefault:"10s"`
+	HealthCheckTimeout  time.Duration `env:"HEALTH_CHECK_TIMEOUT" envDefault:"2s"`
+}
+
 func NewConfig() (*Config, error) {
 	cfg := Config{}
 
@@ -6,3 +6,40 @@ type PostNotFoundError struct {
 func (err PostNotFoundError) Error() string {
 	return "Post not found"
 }
+
+type LikeNotFoundError struct {
+}
+
+func (err LikeNotFoundError) Error() string {
+	return "Like not found"
+}
+
+type InvalidUpdateMaskError struct {
+	Path string
+}
+
+func (err InvalidUpdateMaskError) Error() string {
+	return "Unknown update mask path: " + err.Path
+}
+
+type ProducerBufferFullError struct {
+}
+
+func (err ProducerBufferFullError) Error() string {
+	return "Kafka producer buffer is full"
+}
+
+type ProducerClosedError struct {
+}
+
+func (err ProducerClosedError) Error() string {
+	return "Kafka producer is closed"
+}
+
+type UnknownTracingExporterError struct {
+	Exporter string
+}
+
+func (err UnknownTracingExporterError) Error() string {
+	return "Unknown tracing exporter: " + err.Exporter
+}
@@ -2,14 +2,17 @@ package db
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/config"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/logger"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/models"
 	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/opentelemetry-go-extra/otelsql"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 )
 
@@ -39,6 +42,10 @@ func CreateTables(db *bun.DB) error {
 		logger.Logger.Error("create likes table error", "error", err.Error())
 		return err
 	}
+	if err := createLikesIndex(db); err != nil {
+		logger.Logger.Error("create likes index error", "error", err.Error())
+		return err
+	}
 	if err := CreateTable(db, (*models.DbView)(nil)); err != nil {
 		logger.Logger.Error("create views table error", "error", err.Error())
 		return err
@@ -47,16 +54,41 @@ func CreateTables(db *bun.DB) error {
 	return nil
 }
 
-func InitDb(lc fx.Lifecycle, cfg *config.Config) *bun.DB {
+// createLikesIndex makes a like of a user unique per post, so that liking twice is a no-op.
+// Likes duplicated before the index existed are dropped first, the oldest one is kept.
+func createLikesIndex(db *bun.DB) error {
+	_, err := db.ExecContext(context.Background(), `
+		DELETE FROM likes AS a
+		USING likes AS b
+		WHERE a.post_id = b.post_id AND a.user_id = b.user_id AND a.id > b.id
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.NewCreateIndex().
+		IfNotExists().
+		Model((*models.DbLike)(nil)).
+		Index("likes_post_id_user_id_idx").
+		Unique().
+		Column("post_id", "user_id").
+		Exec(context.Background())
+
+	return err
+}
+
+func InitDb(lc fx.Lifecycle, cfg *config.Config, tp trace.TracerProvider, reg prometheus.Registerer) *bun.DB {
 	dsn := fmt.Sprintf("postgres://%s:%s@%s%s/%s?sslmode=disable",
 		cfg.PostsPostgresUser, cfg.PostsPostgresPassword, cfg.PostsPostgresHost, cfg.PostsPostgresPort, cfg.PostsPostgresDb)
 
-	sqldb, err := sql.Open("pgx", dsn)
+	sqldb, err := otelsql.Open("pgx", dsn, otelsql.WithTracerProvider(tp), otelsql.WithDBSystem("postgresql"))
 	if err != nil {
 		logger.Logger.Error("open database error", "error", err.Error())
 		return nil
 	}
 
+	reg.MustRegister(collectors.NewDBStatsCollector(sqldb, cfg.PostsPostgresDb))
+
 	db := bun.NewDB(sqldb, pgdialect.New())
 	err = CreateTables(db)
 	if err != nil {
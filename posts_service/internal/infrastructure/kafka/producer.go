@@ -3,44 +3,212 @@ package kafka
 import (
 	"context"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/config"
+	svcErrors "github.com/grigorovskiiy/soa-hse/posts_service/internal/errors"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/logger"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 	"hash/adler32"
+	"math/rand/v2"
+	"sync"
+	"time"
 )
 
 type producer interface {
 	WriteMessages(context.Context, ...kafka.Message) error
 }
 
+// DeliveryCallback is called once a batch of messages is written or given up on.
+type DeliveryCallback func(msgs []kafka.Message, err error)
+
+// BaseProducer writes messages to kafka. In async mode Produce only puts the message into a
+// bounded buffer and a background loop writes it in batches; when the buffer is full Produce
+// waits for EnqueueTimeout and then fails, so that callers are slowed down instead of the
+// memory growing. The buffer is flushed when the application stops.
 type BaseProducer struct {
 	producer
+	cfg       *config.Config
+	tracer    trace.Tracer
+	metrics   *ProducerMetrics
+	callbacks []DeliveryCallback
+
+	mu     sync.RWMutex
+	closed bool
+	buffer chan kafka.Message
+	done   chan struct{}
 }
 
-func NewBaseProducer(lc fx.Lifecycle, cfg *config.Config) *BaseProducer {
+func NewBaseProducer(lc fx.Lifecycle, cfg *config.Config, tp trace.TracerProvider, reg prometheus.Registerer) *BaseProducer {
 	writer := &kafka.Writer{
-		Addr:      kafka.TCP(cfg.Brokers...),
-		Balancer:  &kafka.Hash{Hasher: adler32.New()},
-		Transport: kafka.DefaultTransport,
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Balancer:     &kafka.Hash{Hasher: adler32.New()},
+		Transport:    kafka.DefaultTransport,
+		RequiredAcks: kafka.RequiredAcks(cfg.ProducerRequiredAcks),
+		BatchSize:    cfg.ProducerBatchSize,
+		BatchTimeout: cfg.ProducerBatchTimeout,
+		MaxAttempts:  1,
 	}
 
+	p := &BaseProducer{
+		producer: writer,
+		cfg:      cfg,
+		tracer:   tp.Tracer("kafka"),
+		metrics:  NewProducerMetrics(),
+		buffer:   make(chan kafka.Message, cfg.ProducerBufferSize),
+		done:     make(chan struct{}),
+	}
+	p.OnDelivery(p.metrics.delivery)
+	reg.MustRegister(p.metrics)
+
 	lc.Append(fx.Hook{
-		OnStop: func(_ context.Context) error {
+		OnStart: func(_ context.Context) error {
+			if cfg.ProducerAsync {
+				go p.run()
+			} else {
+				close(p.done)
+			}
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			p.mu.Lock()
+			p.closed = true
+			close(p.buffer)
+			p.mu.Unlock()
+
+			select {
+			case <-p.done:
+			case <-ctx.Done():
+				logger.Logger.Error("kafka producer flush error", "error", ctx.Err().Error(), "pending", len(p.buffer))
+			}
+
 			return writer.Close()
 		},
 	})
 
-	return &BaseProducer{writer}
+	return p
+}
+
+// OnDelivery registers a callback for written and failed messages. It must be called before
+// the application is started.
+func (p *BaseProducer) OnDelivery(f DeliveryCallback) {
+	p.callbacks = append(p.callbacks, f)
 }
 
-func (p *BaseProducer) Produce(ctx context.Context, topic string, msg *kafka.Message) error {
+func (p *BaseProducer) Metrics() *ProducerMetrics {
+	return p.metrics
+}
+
+// Produce puts the trace context of ctx into the message headers. In async mode the publish
+// span ends when the message is buffered.
+func (p *BaseProducer) Produce(ctx context.Context, topic string, msg *kafka.Message) (err error) {
 	msg.Topic = topic
-	err := p.WriteMessages(ctx, *msg)
+
+	ctx, span := p.tracer.Start(ctx, topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(semconv.MessagingSystemKafka, semconv.MessagingDestinationName(topic)))
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier{msg: msg})
+	if id := logger.RequestID(ctx); id != "" {
+		headerCarrier{msg: msg}.Set(logger.RequestIDKey, id)
+	}
+
+	if !p.cfg.ProducerAsync {
+		return p.write(ctx, []kafka.Message{*msg})
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return svcErrors.ProducerClosedError{}
+	}
+
+	timer := time.NewTimer(p.cfg.ProducerEnqueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case p.buffer <- *msg:
+		p.metrics.enqueued(topic)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		p.metrics.rejected(topic)
+		logger.FromContext(ctx).Error("kafka producer buffer is full", "topic", topic, "buffer_size", cap(p.buffer))
+		return svcErrors.ProducerBufferFullError{}
+	}
+}
+
+// run writes buffered messages in batches until the buffer is closed and drained.
+func (p *BaseProducer) run() {
+	defer close(p.done)
+
+	for {
+		msg, ok := <-p.buffer
+		if !ok {
+			return
+		}
+
+		batch := []kafka.Message{msg}
+		timer := time.NewTimer(p.cfg.ProducerBatchTimeout)
+	collect:
+		for len(batch) < p.cfg.ProducerBatchSize {
+			select {
+			case msg, ok = <-p.buffer:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, msg)
+			case <-timer.C:
+				break collect
+			}
+		}
+		timer.Stop()
+
+		_ = p.write(context.Background(), batch)
+	}
+}
+
+// write retries failed writes with exponential backoff and jitter and reports the result
+// to the delivery callbacks.
+func (p *BaseProducer) write(ctx context.Context, msgs []kafka.Message) error {
+	start := time.Now()
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = p.WriteMessages(ctx, msgs...); err == nil || attempt >= p.cfg.ProducerMaxRetries {
+			break
+		}
+
+		backoff := p.cfg.ProducerRetryBackoff << attempt
+		backoff += rand.N(backoff/2 + 1)
+		logger.FromContext(ctx).Error("kafka write messages error", "error", err.Error(), "attempt", attempt+1, "backoff", backoff)
+
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-time.After(backoff):
+			continue
+		}
+		break
+	}
 
 	if err != nil {
-		logger.Logger.Error("error producing kafka message", "topic", topic, "error", err.Error())
+		logger.FromContext(ctx).Error("error producing kafka messages", "messages", len(msgs), "error", err.Error())
 	} else {
-		logger.Logger.Info("kafka message produced successfully", "topic", topic)
+		logger.FromContext(ctx).Info("kafka messages produced successfully", "messages", len(msgs))
+	}
+
+	p.metrics.written(time.Since(start), err)
+	for _, f := range p.callbacks {
+		f(msgs, err)
 	}
 
 	return err
@@ -0,0 +1,78 @@
+package kafka
+
+import (
+	"context"
+	"github.com/grigorovskiiy/soa-hse/posts_service/internal/config"
+	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/tracing"
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+)
+
+type recordingProducer struct {
+	msgs []kafka.Message
+}
+
+func (p *recordingProducer) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	p.msgs = append(p.msgs, msgs...)
+	return nil
+}
+
+func TestProducePropagatesTraceContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	exporter := tracetest.NewInMemoryExporter()
+	tp := tracing.NewProvider(exporter, 1)
+	t.Cleanup(func() {
+		_ = tp.Shutdown(context.Background())
+	})
+
+	writer := &recordingProducer{}
+	p := &BaseProducer{
+		producer: writer,
+		cfg:      &config.Config{},
+		tracer:   tp.Tracer("kafka"),
+		metrics:  NewProducerMetrics(),
+	}
+
+	ctx, parent := tp.Tracer("test").Start(logger.WithRequestID(context.Background(), "req-1"), "request")
+	if err := p.Produce(ctx, "likes", &kafka.Message{Value: []byte("{}")}); err != nil {
+		t.Fatalf("produce: %v", err)
+	}
+	parent.End()
+	if err := tp.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("flush spans: %v", err)
+	}
+
+	var publish tracetest.SpanStub
+	for _, s := range exporter.GetSpans() {
+		if s.Name == "likes publish" {
+			publish = s
+		}
+	}
+	if publish.Name == "" {
+		t.Fatalf("no publish span in %d spans", len(exporter.GetSpans()))
+	}
+	if publish.SpanKind != trace.SpanKindProducer {
+		t.Errorf("span kind %v, want producer", publish.SpanKind)
+	}
+	if publish.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("publish span is not a child of the request span")
+	}
+
+	if len(writer.msgs) != 1 {
+		t.Fatalf("%d messages written, want 1", len(writer.msgs))
+	}
+	msg := &writer.msgs[0]
+	sc := trace.SpanContextFromContext(otel.GetTextMapPropagator().Extract(context.Background(), headerCarrier{msg: msg}))
+	if sc.TraceID() != publish.SpanContext.TraceID() || sc.SpanID() != publish.SpanContext.SpanID() {
+		t.Errorf("headers carry %s/%s, want the publish span %s/%s",
+			sc.TraceID(), sc.SpanID(), publish.SpanContext.TraceID(), publish.SpanContext.SpanID())
+	}
+	if id := (headerCarrier{msg: msg}).Get(logger.RequestIDKey); id != "req-1" {
+		t.Errorf("request id header %q, want %q", id, "req-1")
+	}
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/grigorovskiiy/soa-hse/posts_service/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/fx"
+	"net/http"
+)
+
+// Module serves the metrics of the service on MetricsPort at /metrics.
+var Module = fx.Module("metrics",
+	fx.Provide(
+		NewRegistry,
+		func(r *prometheus.Registry) prometheus.Registerer {
+			return r
+		},
+		NewGRPCMetrics,
+		NewPostsMetrics,
+	),
+	fx.Invoke(RunServer),
+)
+
+func NewRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return reg
+}
+
+func RunServer(lc fx.Lifecycle, reg *prometheus.Registry, cfg *config.Config) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{Registry: reg}))
+	server := &http.Server{Addr: cfg.MetricsPort, Handler: mux}
+
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go func() {
+				if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					panic(err)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return server.Shutdown(ctx)
+		},
+	})
+}
+
+// PostsMetrics counts the interactions with posts that were stored.
+type PostsMetrics struct {
+	PostsCreated prometheus.Counter
+	Likes        prometheus.Counter
+	Comments     prometheus.Counter
+	Views        prometheus.Counter
+}
+
+func NewPostsMetrics(reg prometheus.Registerer) *PostsMetrics {
+	m := &PostsMetrics{
+		PostsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "posts_created_total",
+			Help: "Number of created posts.",
+		}),
+		Likes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "posts_likes_total",
+			Help: "Number of likes of posts.",
+		}),
+		Comments: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "posts_comments_total",
+			Help: "Number of comments to posts.",
+		}),
+		Views: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "posts_views_total",
+			Help: "Number of views of posts.",
+		}),
+	}
+	reg.MustRegister(m.PostsCreated, m.Likes, m.Comments, m.Views)
+
+	return m
+}
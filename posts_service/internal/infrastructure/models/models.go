@@ -38,9 +38,3 @@ type DbView struct {
 	PostId        int `bun:"post_id" json:"post_id"`
 	UserId        int `bun:"user_id" json:"user_id"`
 }
-
-type LikeViewCommentUpdate struct {
-	UserId int       `bun:"user_id" json:"user_id"`
-	PostId int       `bun:"post_id" json:"post_id"`
-	Time   time.Time `bun:"time" json:"time"`
-}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	stdErrors "errors"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/errors"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/logger"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/models"
@@ -16,31 +18,90 @@ func NewPRepository(db *bun.DB) *PRepository {
 	return &PRepository{db: db}
 }
 
-func (r *PRepository) GetPost(postId int32, userId int32) (*models.DbPost, error) {
+func (r *PRepository) GetPost(ctx context.Context, postId int32, userId int32) (*models.DbPost, error) {
 	exists, err := r.db.NewSelect().
 		Model((*models.DbPost)(nil)).
 		Where("id = ? and user_id = ?", postId, userId).
-		Exists(context.Background())
+		Exists(ctx)
 	if err != nil {
-		logger.Logger.Error("get post db error", err.Error())
+		logger.FromContext(ctx).Error("exists get post db error", "error", err.Error())
 		return nil, err
 	}
 	if !exists {
-		logger.Logger.Info(errors.PostNotFoundError{}.Error())
+		logger.FromContext(ctx).Info(errors.PostNotFoundError{}.Error())
 		return nil, errors.PostNotFoundError{}
 	}
 
 	var post models.DbPost
-	err = r.db.NewSelect().Model(&post).Where("id = ? and user_id = ?", postId, userId).Scan(context.Background())
+	err = r.db.NewSelect().Model(&post).Where("id = ? and user_id = ?", postId, userId).Scan(ctx)
 	if err != nil {
-		logger.Logger.Error("get post db error", err.Error())
+		logger.FromContext(ctx).Error("get post db error", "error", err.Error())
 		return nil, err
 	}
 
 	return &post, nil
 }
 
-func (r *PRepository) GetPostList(page int32, limit int32, userId int32) ([]*models.DbPost, error) {
+// GetPostAuthor returns the author of a post, it also checks that the post exists.
+func (r *PRepository) GetPostAuthor(ctx context.Context, postId int32) (int32, error) {
+	var post models.DbPost
+	err := r.db.NewSelect().Model(&post).Column("user_id").Where("id = ?", postId).Scan(ctx)
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		logger.FromContext(ctx).Info(errors.PostNotFoundError{}.Error())
+		return 0, errors.PostNotFoundError{}
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("get post author db error", "error", err.Error())
+		return 0, err
+	}
+
+	return int32(post.UserId), nil
+}
+
+func (r *PRepository) GetPostMeta(ctx context.Context, postId int32) (*models.DbPost, error) {
+	exists, err := r.db.NewSelect().
+		Model((*models.DbPost)(nil)).
+		Where("id = ?", postId).
+		Exists(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("exists get post meta db error", "error", err.Error())
+		return nil, err
+	}
+	if !exists {
+		logger.FromContext(ctx).Info(errors.PostNotFoundError{}.Error())
+		return nil, errors.PostNotFoundError{}
+	}
+
+	var post models.DbPost
+	err = r.db.NewSelect().Model(&post).Column("id", "user_id", "security_flag").Where("id = ?", postId).Scan(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("get post meta db error", "error", err.Error())
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+func (r *PRepository) GetPostsMeta(ctx context.Context, postIDs []int32) ([]*models.DbPost, error) {
+	var posts []*models.DbPost
+	if len(postIDs) == 0 {
+		return posts, nil
+	}
+
+	err := r.db.NewSelect().
+		Model(&posts).
+		Column("id", "user_id", "security_flag").
+		Where("id IN (?)", bun.In(postIDs)).
+		Scan(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("get posts meta db error", "error", err.Error())
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+func (r *PRepository) GetPostList(ctx context.Context, page int32, limit int32, userId int32) ([]*models.DbPost, error) {
 	var posts []*models.DbPost
 
 	offset := (page - 1) * limit
@@ -53,150 +114,191 @@ func (r *PRepository) GetPostList(page int32, limit int32, userId int32) ([]*mod
 		Offset(int(offset))
 
 	query = query.WhereOr("user_id = ?", userId)
-	err := query.Scan(context.Background(), &posts)
+	err := query.Scan(ctx, &posts)
 	if err != nil {
-		logger.Logger.Error("scan get post list error", "error", err.Error())
+		logger.FromContext(ctx).Error("scan get post list error", "error", err.Error())
 		return nil, err
 	}
 
 	return posts, nil
 }
 
-func (r *PRepository) UpdatePost(post *models.DbPost) error {
+// UpdatePost updates the given columns of the post, or all non-zero fields when columns are empty.
+func (r *PRepository) UpdatePost(ctx context.Context, post *models.DbPost, columns []string) error {
 	exists, err := r.db.NewSelect().
 		Model((*models.DbPost)(nil)).
 		Where("id = ? and user_id = ?", post.Id, post.UserId).
-		Exists(context.Background())
+		Exists(ctx)
 	if err != nil {
-		logger.Logger.Error("exists update post db error", "error", err.Error())
+		logger.FromContext(ctx).Error("exists update post db error", "error", err.Error())
 		return err
 	}
 	if !exists {
-		logger.Logger.Info(errors.PostNotFoundError{}.Error())
+		logger.FromContext(ctx).Info(errors.PostNotFoundError{}.Error())
 		return errors.PostNotFoundError{}
 	}
 
-	_, err = r.db.NewUpdate().Model(post).Where("id = ? and user_id = ?", post.Id, post.UserId).OmitZero().Exec(context.Background())
+	query := r.db.NewUpdate().Model(post).Where("id = ? and user_id = ?", post.Id, post.UserId)
+	if len(columns) == 0 {
+		query = query.OmitZero()
+	} else {
+		query = query.Column(append(columns, "updated_at")...)
+	}
+
+	_, err = query.Exec(ctx)
 	if err != nil {
-		logger.Logger.Error("execing update post db error", "error", err.Error())
+		logger.FromContext(ctx).Error("execing update post db error", "error", err.Error())
 		return err
 	}
 
 	return nil
 }
 
-func (r *PRepository) DeletePost(postId int32, userId int32) error {
+func (r *PRepository) DeletePost(ctx context.Context, postId int32, userId int32) error {
 	exists, err := r.db.NewSelect().
 		Model((*models.DbPost)(nil)).
 		Where("id = ? and user_id = ?", postId, userId).
-		Exists(context.Background())
+		Exists(ctx)
 	if err != nil {
-		logger.Logger.Error("exists delete post db error", "error", err.Error())
+		logger.FromContext(ctx).Error("exists delete post db error", "error", err.Error())
 		return err
 	}
 	if !exists {
-		logger.Logger.Info(errors.PostNotFoundError{}.Error())
+		logger.FromContext(ctx).Info(errors.PostNotFoundError{}.Error())
 		return errors.PostNotFoundError{}
 	}
 
-	_, err = r.db.NewDelete().Model(&models.DbPost{}).Where("id = ? and user_id = ?", postId, userId).Exec(context.Background())
+	_, err = r.db.NewDelete().Model(&models.DbPost{}).Where("id = ? and user_id = ?", postId, userId).Exec(ctx)
 	if err != nil {
-		logger.Logger.Error("execing delete post db error", "error", err.Error())
+		logger.FromContext(ctx).Error("execing delete post db error", "error", err.Error())
 		return err
 	}
 	return nil
 }
 
-func (r *PRepository) CreatePost(post *models.DbPost) error {
-	_, err := r.db.NewInsert().Model(post).Exec(context.Background())
+func (r *PRepository) CreatePost(ctx context.Context, post *models.DbPost) error {
+	_, err := r.db.NewInsert().Model(post).Exec(ctx)
 	if err != nil {
-		logger.Logger.Error("execing create post db error", "error", err.Error())
+		logger.FromContext(ctx).Error("execing create post db error", "error", err.Error())
 		return err
 	}
 
 	return nil
 }
 
-func (r *PRepository) PostComment(comment *models.DbComment) error {
-	exists, err := r.db.NewSelect().
-		Model((*models.DbPost)(nil)).
-		Where("id = ?", comment.PostId).
-		Exists(context.Background())
+// PostComment returns the author of the commented post.
+func (r *PRepository) PostComment(ctx context.Context, comment *models.DbComment) (int32, error) {
+	authorID, err := r.GetPostAuthor(ctx, int32(comment.PostId))
 	if err != nil {
-		logger.Logger.Error("post comment db error", err.Error())
-		return err
-	}
-	if !exists {
-		logger.Logger.Info(errors.PostNotFoundError{}.Error())
-		return errors.PostNotFoundError{}
+		return 0, err
 	}
 
-	_, err = r.db.NewInsert().Model(comment).Exec(context.Background())
+	_, err = r.db.NewInsert().Model(comment).Exec(ctx)
 	if err != nil {
-		logger.Logger.Error("execing post comment db error", "error", err.Error())
-		return err
+		logger.FromContext(ctx).Error("execing post comment db error", "error", err.Error())
+		return 0, err
 	}
 
-	return nil
+	return authorID, nil
 }
 
-func (r *PRepository) GetCommentList(page int32, limit int32, userID int32) ([]*models.DbComment, error) {
+func (r *PRepository) GetCommentList(ctx context.Context, page int32, limit int32, postID int32, userID int32) ([]*models.DbComment, error) {
 	var comments []*models.DbComment
 	offset := (page - 1) * limit
 	query := r.db.NewSelect().
 		Model(&comments).
 		Limit(int(limit)).
 		Offset(int(offset))
+	if postID != 0 {
+		query = query.Where("post_id = ?", postID)
+	}
 
-	err := query.Scan(context.Background(), &comments)
+	err := query.Scan(ctx, &comments)
 	if err != nil {
-		logger.Logger.Error("scan get post list error", "error", err.Error())
+		logger.FromContext(ctx).Error("scan get post list error", "error", err.Error())
 		return nil, err
 	}
 
 	return comments, nil
 }
 
-func (r *PRepository) PostLike(like *models.DbLike) error {
-	exists, err := r.db.NewSelect().
-		Model((*models.DbPost)(nil)).
-		Where("id = ?", like.PostId).
-		Exists(context.Background())
+// PostLike returns the author of the liked post and whether the like is new, liking a post
+// again changes nothing.
+func (r *PRepository) PostLike(ctx context.Context, like *models.DbLike) (int32, bool, error) {
+	authorID, err := r.GetPostAuthor(ctx, int32(like.PostId))
 	if err != nil {
-		logger.Logger.Error("post comment db error", err.Error())
-		return err
+		return 0, false, err
 	}
-	if !exists {
-		logger.Logger.Info(errors.PostNotFoundError{}.Error())
-		return errors.PostNotFoundError{}
+	res, err := r.db.NewInsert().Model(like).On("CONFLICT (post_id, user_id) DO NOTHING").Exec(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("execing post like db error", "error", err.Error())
+		return 0, false, err
 	}
-	_, err = r.db.NewInsert().Model(like).Exec(context.Background())
+
+	rows, err := res.RowsAffected()
 	if err != nil {
-		logger.Logger.Error("execing post like db error", "error", err.Error())
-		return err
+		logger.FromContext(ctx).Error("post like rows affected error", "error", err.Error())
+		return 0, false, err
 	}
 
-	return nil
+	return authorID, rows > 0, nil
 }
 
-func (r *PRepository) PostView(view *models.DbView) error {
-	exists, err := r.db.NewSelect().
-		Model((*models.DbPost)(nil)).
-		Where("id = ?", view.PostId).
-		Exists(context.Background())
+func (r *PRepository) DeletePostLike(ctx context.Context, like *models.DbLike) error {
+	res, err := r.db.NewDelete().
+		Model((*models.DbLike)(nil)).
+		Where("post_id = ?", like.PostId).
+		Where("user_id = ?", like.UserId).
+		Exec(ctx)
 	if err != nil {
-		logger.Logger.Error("post comment db error", err.Error())
+		logger.FromContext(ctx).Error("execing delete post like db error", "error", err.Error())
 		return err
 	}
-	if !exists {
-		logger.Logger.Info(errors.PostNotFoundError{}.Error())
-		return errors.PostNotFoundError{}
-	}
-	_, err = r.db.NewInsert().Model(view).Exec(context.Background())
+
+	rows, err := res.RowsAffected()
 	if err != nil {
-		logger.Logger.Error("execing post view db error", "error", err.Error())
+		logger.FromContext(ctx).Error("delete post like rows affected error", "error", err.Error())
 		return err
 	}
+	if rows == 0 {
+		logger.FromContext(ctx).Info(errors.LikeNotFoundError{}.Error())
+		return errors.LikeNotFoundError{}
+	}
 
 	return nil
 }
+
+func (r *PRepository) GetLikedPostIDs(ctx context.Context, userID int32, postIDs []int32) ([]int32, error) {
+	var liked []int32
+	if len(postIDs) == 0 {
+		return liked, nil
+	}
+
+	err := r.db.NewSelect().
+		Model((*models.DbLike)(nil)).
+		ColumnExpr("DISTINCT post_id").
+		Where("user_id = ?", userID).
+		Where("post_id IN (?)", bun.In(postIDs)).
+		Scan(ctx, &liked)
+	if err != nil {
+		logger.FromContext(ctx).Error("get liked post ids db error", "error", err.Error())
+		return nil, err
+	}
+
+	return liked, nil
+}
+
+// PostView returns the author of the viewed post.
+func (r *PRepository) PostView(ctx context.Context, view *models.DbView) (int32, error) {
+	authorID, err := r.GetPostAuthor(ctx, int32(view.PostId))
+	if err != nil {
+		return 0, err
+	}
+	_, err = r.db.NewInsert().Model(view).Exec(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("execing post view db error", "error", err.Error())
+		return 0, err
+	}
+
+	return authorID, nil
+}
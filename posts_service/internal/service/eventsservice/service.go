@@ -2,11 +2,27 @@ package eventsservice
 
 import (
 	"context"
-	"encoding/json"
+	"github.com/google/uuid"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/config"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/kafka"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/logger"
+	pb "github.com/grigorovskiiy/soa-hse/protos"
 	kafkaGo "github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"strconv"
+)
+
+const (
+	ContentTypeHeader  = "content-type"
+	EventTypeHeader    = "event-type"
+	EventVersionHeader = "event-version"
+
+	ContentTypeProtobuf = "application/x-protobuf"
+	// EventVersion is the version of pb.EventEnvelope, version 1 was the plain JSON payload.
+	EventVersion = 2
+
+	producerName = "posts_service"
 )
 
 type KafkaService struct {
@@ -14,55 +30,101 @@ type KafkaService struct {
 }
 
 func NewKafkaService(cfg *config.Config, producer *kafka.BaseProducer) (*KafkaService, error) {
-	client := kafkaGo.Client{
-		Addr: kafkaGo.TCP(cfg.Brokers...),
-	}
-
-	topicsReq := CreateTopicsReq(cfg)
-	_, err := client.CreateTopics(context.Background(), &topicsReq)
-	if err != nil {
-		logger.Logger.Error("error creating topics", "error", err.Error())
+	if err := kafka.ReconcileTopics(context.Background(), cfg, TopicSpecs(cfg)); err != nil {
+		logger.Logger.Error("error reconciling topics", "error", err.Error())
 		return nil, err
 	}
 
 	return &KafkaService{producer: producer}, nil
 }
 
-func (s *KafkaService) SendEvent(ctx context.Context, topic string, upd any) error {
-	msg, err := json.Marshal(upd)
+func (s *KafkaService) PublishPostViewed(ctx context.Context, topic string, event *pb.InteractionEvent) error {
+	return s.publishInteraction(ctx, topic, pb.EventType_POST_VIEWED, event)
+}
+
+func (s *KafkaService) PublishPostLiked(ctx context.Context, topic string, event *pb.InteractionEvent) error {
+	return s.publishInteraction(ctx, topic, pb.EventType_POST_LIKED, event)
+}
+
+// PublishPostUnliked is sent to the topic of the likes, so that it stays ordered after the like
+// it removes.
+func (s *KafkaService) PublishPostUnliked(ctx context.Context, topic string, event *pb.InteractionEvent) error {
+	return s.publishInteraction(ctx, topic, pb.EventType_POST_UNLIKED, event)
+}
+
+func (s *KafkaService) PublishPostCommented(ctx context.Context, topic string, event *pb.InteractionEvent) error {
+	return s.publishInteraction(ctx, topic, pb.EventType_POST_COMMENTED, event)
+}
+
+func (s *KafkaService) PublishPostCreated(ctx context.Context, topic string, event *pb.PostEvent) error {
+	env := NewEnvelope(pb.EventType_POST_CREATED)
+	env.Payload = &pb.EventEnvelope_PostCreated{PostCreated: event}
+
+	return s.publish(ctx, topic, event.PostId, env)
+}
+
+func (s *KafkaService) PublishPostUpdated(ctx context.Context, topic string, event *pb.PostEvent) error {
+	env := NewEnvelope(pb.EventType_POST_UPDATED)
+	env.Payload = &pb.EventEnvelope_PostUpdated{PostUpdated: event}
+
+	return s.publish(ctx, topic, event.PostId, env)
+}
+
+func (s *KafkaService) PublishPostDeleted(ctx context.Context, topic string, event *pb.PostDeletedEvent) error {
+	env := NewEnvelope(pb.EventType_POST_DELETED)
+	env.Payload = &pb.EventEnvelope_PostDeleted{PostDeleted: event}
+
+	return s.publish(ctx, topic, event.PostId, env)
+}
+
+func (s *KafkaService) publishInteraction(ctx context.Context, topic string, eventType pb.EventType, event *pb.InteractionEvent) error {
+	env := NewEnvelope(eventType)
+	env.Payload = &pb.EventEnvelope_Interaction{Interaction: event}
+
+	return s.publish(ctx, topic, event.PostId, env)
+}
+
+// publish sends the envelope keyed by key, so that events with the same key stay in one
+// partition and keep their order.
+func (s *KafkaService) publish(ctx context.Context, topic string, key int32, env *pb.EventEnvelope) error {
+	msg, err := proto.Marshal(env)
 	if err != nil {
-		logger.Logger.Error("upd json marshal error", "error", err.Error())
+		logger.FromContext(ctx).Error("event proto marshal error", "error", err.Error())
 		return err
 	}
 
 	if err := s.producer.Produce(ctx, topic, &kafkaGo.Message{
+		Key:   []byte(strconv.Itoa(int(key))),
 		Value: msg,
+		Headers: []kafkaGo.Header{
+			{Key: ContentTypeHeader, Value: []byte(ContentTypeProtobuf)},
+			{Key: EventTypeHeader, Value: []byte(env.Type.String())},
+			{Key: EventVersionHeader, Value: []byte(strconv.Itoa(int(env.Version)))},
+		},
 	}); err != nil {
-		logger.Logger.Error("kafka produce error", "error", err.Error())
+		logger.FromContext(ctx).Error("kafka produce error", "error", err.Error())
 		return err
 	}
 
 	return nil
 }
 
-func CreateTopicsReq(cfg *config.Config) kafkaGo.CreateTopicsRequest {
-	return kafkaGo.CreateTopicsRequest{
-		Topics: []kafkaGo.TopicConfig{
-			{
-				Topic:             cfg.ViewsTopic,
-				NumPartitions:     1,
-				ReplicationFactor: 1,
-			},
-			{
-				Topic:             cfg.CommentsTopic,
-				NumPartitions:     1,
-				ReplicationFactor: 1,
-			},
-			{
-				Topic:             cfg.LikesTopic,
-				NumPartitions:     1,
-				ReplicationFactor: 1,
-			},
-		},
+// NewEnvelope returns an envelope of the current version without a payload.
+func NewEnvelope(eventType pb.EventType) *pb.EventEnvelope {
+	return &pb.EventEnvelope{
+		EventId:    uuid.NewString(),
+		Type:       eventType,
+		Version:    EventVersion,
+		OccurredAt: timestamppb.Now(),
+		Producer:   producerName,
+	}
+}
+
+func TopicSpecs(cfg *config.Config) map[string]config.TopicSpec {
+	return map[string]config.TopicSpec{
+		cfg.CommentsTopic: cfg.CommentsTopicSpec,
+		cfg.LikesTopic:    cfg.LikesTopicSpec,
+		cfg.ViewsTopic:    cfg.ViewsTopicSpec,
+		cfg.PostsTopic:    cfg.PostsTopicSpec,
 	}
 }
@@ -2,6 +2,7 @@ package postsservice
 
 import (
 	"context"
+	"github.com/grigorovskiiy/soa-hse/posts_service/internal/errors"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/logger"
 	"github.com/grigorovskiiy/soa-hse/posts_service/internal/infrastructure/models"
 	pb "github.com/grigorovskiiy/soa-hse/protos"
@@ -10,16 +11,46 @@ import (
 )
 
 type PostsRepository interface {
-	CreatePost(*models.DbPost) error
-	DeletePost(int32, int32) error
-	UpdatePost(*models.DbPost) error
-	GetPost(int32, int32) (*models.DbPost, error)
-	GetPostList(int32, int32, int32) ([]*models.DbPost, error)
-	PostComment(*models.DbComment) error
-	PostLike(*models.DbLike) error
-	PostView(*models.DbView) error
-	GetCommentList(int32, int32, int32) ([]*models.DbComment, error)
+	CreatePost(context.Context, *models.DbPost) error
+	DeletePost(context.Context, int32, int32) error
+	UpdatePost(context.Context, *models.DbPost, []string) error
+	GetPost(context.Context, int32, int32) (*models.DbPost, error)
+	GetPostAuthor(context.Context, int32) (int32, error)
+	GetPostMeta(context.Context, int32) (*models.DbPost, error)
+	GetPostsMeta(context.Context, []int32) ([]*models.DbPost, error)
+	GetPostList(context.Context, int32, int32, int32) ([]*models.DbPost, error)
+	PostComment(context.Context, *models.DbComment) (int32, error)
+	PostLike(context.Context, *models.DbLike) (int32, bool, error)
+	DeletePostLike(context.Context, *models.DbLike) error
+	GetLikedPostIDs(context.Context, int32, []int32) ([]int32, error)
+	PostView(context.Context, *models.DbView) (int32, error)
+	GetCommentList(context.Context, int32, int32, int32, int32) ([]*models.DbComment, error)
 }
+
+// postColumns maps fields of pb.PostDataRequest to the columns of the posts table.
+var postColumns = map[string]string{
+	"post_name":        "name",
+	"post_description": "description",
+	"security_flag":    "security_flag",
+	"tags":             "tags",
+}
+
+const (
+	defaultPage     = 1
+	defaultPageSize = 10
+)
+
+func paginate(page, pageSize int32) (int32, int32) {
+	if page < 1 {
+		page = defaultPage
+	}
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+
+	return page, pageSize
+}
+
 type Service struct {
 	repository PostsRepository
 }
@@ -28,7 +59,7 @@ func NewService(repository PostsRepository) *Service {
 	return &Service{repository: repository}
 }
 
-func (s *Service) CreatePost(_ context.Context, pb *pb.PostDataRequest, userID int32) error {
+func (s *Service) CreatePost(ctx context.Context, pb *pb.PostDataRequest, userID int32) (*pb.PostDataResponse, error) {
 	post := models.DbPost{
 		Name:         pb.PostName,
 		Tags:         pb.Tags,
@@ -39,66 +70,162 @@ func (s *Service) CreatePost(_ context.Context, pb *pb.PostDataRequest, userID i
 		SecurityFlag: pb.SecurityFlag,
 	}
 
-	if err := s.repository.CreatePost(&post); err != nil {
-		logger.Logger.Error("create post error", "error", err.Error())
-		return err
+	if err := s.repository.CreatePost(ctx, &post); err != nil {
+		logger.FromContext(ctx).Error("create post error", "error", err.Error())
+		return nil, err
 	}
 
-	return nil
+	return toPostDataResponse(&post), nil
 }
 
-func (s *Service) DeletePost(_ context.Context, pb *pb.PostID, userID int32) error {
-	if err := s.repository.DeletePost(pb.PostId, userID); err != nil {
-		logger.Logger.Error("delete post error", "error", err.Error())
+func (s *Service) DeletePost(ctx context.Context, pb *pb.PostID, userID int32) error {
+	if err := s.repository.DeletePost(ctx, pb.PostId, userID); err != nil {
+		logger.FromContext(ctx).Error("delete post error", "error", err.Error())
 		return err
 	}
 
 	return nil
 }
 
-func (s *Service) UpdatePost(_ context.Context, pb *pb.UpdatePostRequest, userID int32) error {
+// UpdatePost returns the post as it is stored after the update, fields left empty in the
+// request are not changed.
+func (s *Service) UpdatePost(ctx context.Context, pb *pb.UpdatePostRequest, userID int32) (*pb.PostDataResponse, error) {
 	post := models.DbPost{
 		UpdatedAt:    time.Now(),
 		Id:           int(pb.PostId),
-		Name:         pb.PostData.PostName,
-		Description:  pb.PostData.PostDescription,
-		SecurityFlag: pb.PostData.SecurityFlag,
-		Tags:         pb.PostData.Tags,
+		Name:         pb.GetPostData().GetPostName(),
+		Description:  pb.GetPostData().GetPostDescription(),
+		SecurityFlag: pb.GetPostData().GetSecurityFlag(),
+		Tags:         pb.GetPostData().GetTags(),
 		UserId:       int(userID),
 	}
 
-	if err := s.repository.UpdatePost(&post); err != nil {
-		logger.Logger.Error("update post error", "error", err.Error())
+	columns := make([]string, 0, len(pb.GetUpdateMask().GetPaths()))
+	for _, path := range pb.GetUpdateMask().GetPaths() {
+		column, ok := postColumns[path]
+		if !ok {
+			return nil, errors.InvalidUpdateMaskError{Path: path}
+		}
+		columns = append(columns, column)
+	}
+
+	if err := s.repository.UpdatePost(ctx, &post, columns); err != nil {
+		logger.FromContext(ctx).Error("update post error", "error", err.Error())
+		return nil, err
+	}
+
+	updated, err := s.repository.GetPost(ctx, pb.PostId, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error("get updated post error", "error", err.Error())
+		return nil, err
+	}
+
+	return toPostDataResponse(updated), nil
+}
+
+func (s *Service) GetPost(ctx context.Context, p *pb.PostID, userID int32) (*pb.PostDataResponse, error) {
+	postInfo, err := s.repository.GetPost(ctx, p.PostId, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error("get post info error", "error", err.Error())
+		return nil, err
+	}
+
+	post := toPostDataResponse(postInfo)
+	if err = s.markLiked(ctx, []*pb.PostDataResponse{post}, userID); err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// markLiked sets LikedByMe for the posts liked by the user.
+func (s *Service) markLiked(ctx context.Context, posts []*pb.PostDataResponse, userID int32) error {
+	postIDs := make([]int32, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.PostId
+	}
+
+	liked, err := s.repository.GetLikedPostIDs(ctx, userID, postIDs)
+	if err != nil {
+		logger.FromContext(ctx).Error("get liked posts error", "error", err.Error())
 		return err
 	}
 
+	likedSet := make(map[int32]bool, len(liked))
+	for _, id := range liked {
+		likedSet[id] = true
+	}
+	for _, post := range posts {
+		post.LikedByMe = likedSet[post.PostId]
+	}
+
 	return nil
 }
 
-func (s *Service) GetPost(_ context.Context, p *pb.PostID, userID int32) (*pb.PostDataResponse, error) {
-	postInfo, err := s.repository.GetPost(p.PostId, userID)
+func toPostDataResponse(post *models.DbPost) *pb.PostDataResponse {
+	return &pb.PostDataResponse{
+		PostId:          int32(post.Id),
+		Tags:            post.Tags,
+		PostName:        post.Name,
+		PostDescription: post.Description,
+		SecurityFlag:    post.SecurityFlag,
+		CreatedAt:       timestamppb.New(post.CreatedAt),
+		UpdatedAt:       timestamppb.New(post.UpdatedAt),
+		UserId:          int32(post.UserId),
+	}
+}
+
+func (s *Service) GetPostAuthor(ctx context.Context, postID int32) (int32, error) {
+	authorID, err := s.repository.GetPostAuthor(ctx, postID)
 	if err != nil {
-		logger.Logger.Error("get post info error", "error", err.Error())
+		logger.FromContext(ctx).Error("get post author error", "error", err.Error())
+		return 0, err
+	}
+
+	return authorID, nil
+}
+
+// GetPostMeta returns the owner and visibility of a post for other services, it does not
+// check who is asking.
+func (s *Service) GetPostMeta(ctx context.Context, p *pb.PostID) (*pb.PostMetaResponse, error) {
+	post, err := s.repository.GetPostMeta(ctx, p.PostId)
+	if err != nil {
+		logger.FromContext(ctx).Error("get post meta error", "error", err.Error())
 		return nil, err
 	}
 
-	pb := pb.PostDataResponse{
-		PostId:          int32(postInfo.Id),
-		Tags:            postInfo.Tags,
-		PostName:        postInfo.Name,
-		PostDescription: postInfo.Description,
-		SecurityFlag:    postInfo.SecurityFlag,
-		CreatedAt:       timestamppb.New(postInfo.CreatedAt),
-		UpdatedAt:       timestamppb.New(postInfo.UpdatedAt),
+	return &pb.PostMetaResponse{
+		PostId:       int32(post.Id),
+		AuthorId:     int32(post.UserId),
+		SecurityFlag: post.SecurityFlag,
+	}, nil
+}
+
+// GetPostsMeta is GetPostMeta for several posts, unknown posts are skipped.
+func (s *Service) GetPostsMeta(ctx context.Context, p *pb.BatchPostsMetaRequest) (*pb.BatchPostsMetaResponse, error) {
+	posts, err := s.repository.GetPostsMeta(ctx, p.PostIds)
+	if err != nil {
+		logger.FromContext(ctx).Error("get posts meta error", "error", err.Error())
+		return nil, err
 	}
 
-	return &pb, nil
+	res := &pb.BatchPostsMetaResponse{Posts: make([]*pb.PostMetaResponse, len(posts))}
+	for i, post := range posts {
+		res.Posts[i] = &pb.PostMetaResponse{
+			PostId:       int32(post.Id),
+			AuthorId:     int32(post.UserId),
+			SecurityFlag: post.SecurityFlag,
+		}
+	}
+
+	return res, nil
 }
 
-func (s *Service) GetPostList(_ context.Context, p *pb.PaginatedListRequest, userID int32) (*pb.ListPostsResponse, error) {
-	posts, err := s.repository.GetPostList(p.Page, p.PageSize, userID)
+func (s *Service) GetPostList(ctx context.Context, p *pb.PaginatedListRequest, userID int32) (*pb.ListPostsResponse, error) {
+	page, pageSize := paginate(p.Page, p.PageSize)
+	posts, err := s.repository.GetPostList(ctx, page, pageSize, userID)
 	if err != nil {
-		logger.Logger.Error("get post list error", "error", err.Error())
+		logger.FromContext(ctx).Error("get post list error", "error", err.Error())
 		return nil, err
 	}
 	pbPosts := pb.ListPostsResponse{
@@ -117,56 +244,82 @@ func (s *Service) GetPostList(_ context.Context, p *pb.PaginatedListRequest, use
 		}
 	}
 
+	if err = s.markLiked(ctx, pbPosts.Posts, userID); err != nil {
+		return nil, err
+	}
+
 	return &pbPosts, nil
 }
 
-func (s *Service) PostComment(ctx context.Context, pb *pb.PostCommentRequest, userID int32) error {
+// PostComment returns the author of the commented post.
+func (s *Service) PostComment(ctx context.Context, pb *pb.PostCommentRequest, userID int32) (int32, error) {
 	comment := models.DbComment{
 		PostId:      int(pb.PostId),
 		UserId:      int(userID),
 		Description: pb.CommentDescription,
 	}
 
-	if err := s.repository.PostComment(&comment); err != nil {
-		logger.Logger.Error("post comment error", "error", err.Error())
-		return err
+	authorID, err := s.repository.PostComment(ctx, &comment)
+	if err != nil {
+		logger.FromContext(ctx).Error("post comment error", "error", err.Error())
+		return 0, err
 	}
 
-	return nil
+	return authorID, nil
+}
+
+// PostLike returns the author of the liked post and whether the post was not liked by the user
+// before.
+func (s *Service) PostLike(ctx context.Context, pb *pb.PostID, userID int32) (int32, bool, error) {
+	like := models.DbLike{
+		PostId: int(pb.PostId),
+		UserId: int(userID),
+	}
+
+	authorID, liked, err := s.repository.PostLike(ctx, &like)
+	if err != nil {
+		logger.FromContext(ctx).Error("post like error", "error", err.Error())
+		return 0, false, err
+	}
+
+	return authorID, liked, nil
 }
 
-func (s *Service) PostLike(ctx context.Context, pb *pb.PostID, userID int32) error {
+func (s *Service) DeletePostLike(ctx context.Context, pb *pb.PostID, userID int32) error {
 	like := models.DbLike{
 		PostId: int(pb.PostId),
 		UserId: int(userID),
 	}
 
-	if err := s.repository.PostLike(&like); err != nil {
-		logger.Logger.Error("post like error", "error", err.Error())
+	if err := s.repository.DeletePostLike(ctx, &like); err != nil {
+		logger.FromContext(ctx).Error("delete post like error", "error", err.Error())
 		return err
 	}
 
 	return nil
 }
 
-func (s *Service) PostView(ctx context.Context, pb *pb.PostID, userID int32) error {
+// PostView returns the author of the viewed post.
+func (s *Service) PostView(ctx context.Context, pb *pb.PostID, userID int32) (int32, error) {
 	view := models.DbView{
 		PostId: int(pb.PostId),
 		UserId: int(userID),
 	}
 
-	if err := s.repository.PostView(&view); err != nil {
-		logger.Logger.Error("post view error", "error", err.Error())
-		return err
+	authorID, err := s.repository.PostView(ctx, &view)
+	if err != nil {
+		logger.FromContext(ctx).Error("post view error", "error", err.Error())
+		return 0, err
 	}
 
-	return nil
+	return authorID, nil
 }
 
-func (s *Service) GetCommentList(_ context.Context, p *pb.PaginatedListRequest, userID int32) (*pb.ListCommentsResponse, error) {
-	comments, err := s.repository.GetCommentList(p.Page, p.PageSize, userID)
+func (s *Service) GetCommentList(ctx context.Context, p *pb.PaginatedListRequest, userID int32) (*pb.ListCommentsResponse, error) {
+	page, pageSize := paginate(p.Page, p.PageSize)
+	comments, err := s.repository.GetCommentList(ctx, page, pageSize, p.PostId, userID)
 	if err != nil {
-		logger.Logger.Error("get comment list error", "error", err.Error())
+		logger.FromContext(ctx).Error("get comment list error", "error", err.Error())
 		return nil, err
 	}
 
@@ -0,0 +1,8 @@
+package protos
+
+import _ "embed"
+
+// OpenAPI is the specification of the HTTP API generated from the google.api.http annotations of soa.proto.
+//
+//go:embed soa.swagger.json
+var OpenAPI []byte
@@ -30,8 +30,12 @@ type PostsServiceClient interface {
 	GetPostList(ctx context.Context, in *PaginatedListRequest, opts ...grpc.CallOption) (*ListPostsResponse, error)
 	PostComment(ctx context.Context, in *PostCommentRequest, opts ...grpc.CallOption) (*empty.Empty, error)
 	PostLike(ctx context.Context, in *PostID, opts ...grpc.CallOption) (*empty.Empty, error)
+	DeletePostLike(ctx context.Context, in *PostID, opts ...grpc.CallOption) (*empty.Empty, error)
 	PostView(ctx context.Context, in *PostID, opts ...grpc.CallOption) (*empty.Empty, error)
 	GetCommentList(ctx context.Context, in *PaginatedListRequest, opts ...grpc.CallOption) (*ListCommentsResponse, error)
+	GetPostMeta(ctx context.Context, in *PostID, opts ...grpc.CallOption) (*PostMetaResponse, error)
+	// GetPostsMeta skips unknown posts, it is meant for other services and is not exposed over HTTP.
+	GetPostsMeta(ctx context.Context, in *BatchPostsMetaRequest, opts ...grpc.CallOption) (*BatchPostsMetaResponse, error)
 }
 
 type postsServiceClient struct {
@@ -105,6 +109,15 @@ func (c *postsServiceClient) PostLike(ctx context.Context, in *PostID, opts ...g
 	return out, nil
 }
 
+func (c *postsServiceClient) DeletePostLike(ctx context.Context, in *PostID, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/posts_service.PostsService/DeletePostLike", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *postsServiceClient) PostView(ctx context.Context, in *PostID, opts ...grpc.CallOption) (*empty.Empty, error) {
 	out := new(empty.Empty)
 	err := c.cc.Invoke(ctx, "/posts_service.PostsService/PostView", in, out, opts...)
@@ -123,6 +136,24 @@ func (c *postsServiceClient) GetCommentList(ctx context.Context, in *PaginatedLi
 	return out, nil
 }
 
+func (c *postsServiceClient) GetPostMeta(ctx context.Context, in *PostID, opts ...grpc.CallOption) (*PostMetaResponse, error) {
+	out := new(PostMetaResponse)
+	err := c.cc.Invoke(ctx, "/posts_service.PostsService/GetPostMeta", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postsServiceClient) GetPostsMeta(ctx context.Context, in *BatchPostsMetaRequest, opts ...grpc.CallOption) (*BatchPostsMetaResponse, error) {
+	out := new(BatchPostsMetaResponse)
+	err := c.cc.Invoke(ctx, "/posts_service.PostsService/GetPostsMeta", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PostsServiceServer is the server API for PostsService service.
 // All implementations must embed UnimplementedPostsServiceServer
 // for forward compatibility
@@ -134,8 +165,12 @@ type PostsServiceServer interface {
 	GetPostList(context.Context, *PaginatedListRequest) (*ListPostsResponse, error)
 	PostComment(context.Context, *PostCommentRequest) (*empty.Empty, error)
 	PostLike(context.Context, *PostID) (*empty.Empty, error)
+	DeletePostLike(context.Context, *PostID) (*empty.Empty, error)
 	PostView(context.Context, *PostID) (*empty.Empty, error)
 	GetCommentList(context.Context, *PaginatedListRequest) (*ListCommentsResponse, error)
+	GetPostMeta(context.Context, *PostID) (*PostMetaResponse, error)
+	// GetPostsMeta skips unknown posts, it is meant for other services and is not exposed over HTTP.
+	GetPostsMeta(context.Context, *BatchPostsMetaRequest) (*BatchPostsMetaResponse, error)
 	mustEmbedUnimplementedPostsServiceServer()
 }
 
@@ -164,12 +199,21 @@ func (UnimplementedPostsServiceServer) PostComment(context.Context, *PostComment
 func (UnimplementedPostsServiceServer) PostLike(context.Context, *PostID) (*empty.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PostLike not implemented")
 }
+func (UnimplementedPostsServiceServer) DeletePostLike(context.Context, *PostID) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePostLike not implemented")
+}
 func (UnimplementedPostsServiceServer) PostView(context.Context, *PostID) (*empty.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PostView not implemented")
 }
 func (UnimplementedPostsServiceServer) GetCommentList(context.Context, *PaginatedListRequest) (*ListCommentsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetCommentList not implemented")
 }
+func (UnimplementedPostsServiceServer) GetPostMeta(context.Context, *PostID) (*PostMetaResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPostMeta not implemented")
+}
+func (UnimplementedPostsServiceServer) GetPostsMeta(context.Context, *BatchPostsMetaRequest) (*BatchPostsMetaResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPostsMeta not implemented")
+}
 func (UnimplementedPostsServiceServer) mustEmbedUnimplementedPostsServiceServer() {}
 
 // UnsafePostsServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -309,6 +353,24 @@ func _PostsService_PostLike_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PostsService_DeletePostLike_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PostID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostsServiceServer).DeletePostLike(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/posts_service.PostsService/DeletePostLike",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostsServiceServer).DeletePostLike(ctx, req.(*PostID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _PostsService_PostView_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PostID)
 	if err := dec(in); err != nil {
@@ -345,6 +407,42 @@ func _PostsService_GetCommentList_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PostsService_GetPostMeta_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PostID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostsServiceServer).GetPostMeta(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/posts_service.PostsService/GetPostMeta",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostsServiceServer).GetPostMeta(ctx, req.(*PostID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostsService_GetPostsMeta_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchPostsMetaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostsServiceServer).GetPostsMeta(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/posts_service.PostsService/GetPostsMeta",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostsServiceServer).GetPostsMeta(ctx, req.(*BatchPostsMetaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PostsService_ServiceDesc is the grpc.ServiceDesc for PostsService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -380,6 +478,10 @@ var PostsService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "PostLike",
 			Handler:    _PostsService_PostLike_Handler,
 		},
+		{
+			MethodName: "DeletePostLike",
+			Handler:    _PostsService_DeletePostLike_Handler,
+		},
 		{
 			MethodName: "PostView",
 			Handler:    _PostsService_PostView_Handler,
@@ -388,6 +490,14 @@ var PostsService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetCommentList",
 			Handler:    _PostsService_GetCommentList_Handler,
 		},
+		{
+			MethodName: "GetPostMeta",
+			Handler:    _PostsService_GetPostMeta_Handler,
+		},
+		{
+			MethodName: "GetPostsMeta",
+			Handler:    _PostsService_GetPostsMeta_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "protos/soa.proto",
@@ -403,8 +513,15 @@ type StatisticServiceClient interface {
 	GetViewsDynamic(ctx context.Context, in *PostID, opts ...grpc.CallOption) (*DynamicListResponse, error)
 	GetCommentsDynamic(ctx context.Context, in *PostID, opts ...grpc.CallOption) (*DynamicListResponse, error)
 	GetLikesDynamic(ctx context.Context, in *PostID, opts ...grpc.CallOption) (*DynamicListResponse, error)
+	GetUniqueViewersCount(ctx context.Context, in *PostID, opts ...grpc.CallOption) (*CountResponse, error)
+	GetUniqueLikersCount(ctx context.Context, in *PostID, opts ...grpc.CallOption) (*CountResponse, error)
+	GetUniqueViewersDynamic(ctx context.Context, in *PostID, opts ...grpc.CallOption) (*DynamicListResponse, error)
+	GetUniqueLikersDynamic(ctx context.Context, in *PostID, opts ...grpc.CallOption) (*DynamicListResponse, error)
 	GetTopTenPosts(ctx context.Context, in *TopTenParameter, opts ...grpc.CallOption) (*TopTenPostsResponse, error)
 	GetTopTenUsers(ctx context.Context, in *TopTenParameter, opts ...grpc.CallOption) (*TopTenUsersResponse, error)
+	GetAuthorStats(ctx context.Context, in *UserID, opts ...grpc.CallOption) (*AuthorStatsResponse, error)
+	// BatchGetPostStats skips posts that are not visible to the caller.
+	BatchGetPostStats(ctx context.Context, in *BatchPostStatsRequest, opts ...grpc.CallOption) (*BatchPostStatsResponse, error)
 }
 
 type statisticServiceClient struct {
@@ -469,6 +586,42 @@ func (c *statisticServiceClient) GetLikesDynamic(ctx context.Context, in *PostID
 	return out, nil
 }
 
+func (c *statisticServiceClient) GetUniqueViewersCount(ctx context.Context, in *PostID, opts ...grpc.CallOption) (*CountResponse, error) {
+	out := new(CountResponse)
+	err := c.cc.Invoke(ctx, "/posts_service.StatisticService/GetUniqueViewersCount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statisticServiceClient) GetUniqueLikersCount(ctx context.Context, in *PostID, opts ...grpc.CallOption) (*CountResponse, error) {
+	out := new(CountResponse)
+	err := c.cc.Invoke(ctx, "/posts_service.StatisticService/GetUniqueLikersCount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statisticServiceClient) GetUniqueViewersDynamic(ctx context.Context, in *PostID, opts ...grpc.CallOption) (*DynamicListResponse, error) {
+	out := new(DynamicListResponse)
+	err := c.cc.Invoke(ctx, "/posts_service.StatisticService/GetUniqueViewersDynamic", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statisticServiceClient) GetUniqueLikersDynamic(ctx context.Context, in *PostID, opts ...grpc.CallOption) (*DynamicListResponse, error) {
+	out := new(DynamicListResponse)
+	err := c.cc.Invoke(ctx, "/posts_service.StatisticService/GetUniqueLikersDynamic", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *statisticServiceClient) GetTopTenPosts(ctx context.Context, in *TopTenParameter, opts ...grpc.CallOption) (*TopTenPostsResponse, error) {
 	out := new(TopTenPostsResponse)
 	err := c.cc.Invoke(ctx, "/posts_service.StatisticService/GetTopTenPosts", in, out, opts...)
@@ -487,6 +640,24 @@ func (c *statisticServiceClient) GetTopTenUsers(ctx context.Context, in *TopTenP
 	return out, nil
 }
 
+func (c *statisticServiceClient) GetAuthorStats(ctx context.Context, in *UserID, opts ...grpc.CallOption) (*AuthorStatsResponse, error) {
+	out := new(AuthorStatsResponse)
+	err := c.cc.Invoke(ctx, "/posts_service.StatisticService/GetAuthorStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statisticServiceClient) BatchGetPostStats(ctx context.Context, in *BatchPostStatsRequest, opts ...grpc.CallOption) (*BatchPostStatsResponse, error) {
+	out := new(BatchPostStatsResponse)
+	err := c.cc.Invoke(ctx, "/posts_service.StatisticService/BatchGetPostStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // StatisticServiceServer is the server API for StatisticService service.
 // All implementations must embed UnimplementedStatisticServiceServer
 // for forward compatibility
@@ -497,8 +668,15 @@ type StatisticServiceServer interface {
 	GetViewsDynamic(context.Context, *PostID) (*DynamicListResponse, error)
 	GetCommentsDynamic(context.Context, *PostID) (*DynamicListResponse, error)
 	GetLikesDynamic(context.Context, *PostID) (*DynamicListResponse, error)
+	GetUniqueViewersCount(context.Context, *PostID) (*CountResponse, error)
+	GetUniqueLikersCount(context.Context, *PostID) (*CountResponse, error)
+	GetUniqueViewersDynamic(context.Context, *PostID) (*DynamicListResponse, error)
+	GetUniqueLikersDynamic(context.Context, *PostID) (*DynamicListResponse, error)
 	GetTopTenPosts(context.Context, *TopTenParameter) (*TopTenPostsResponse, error)
 	GetTopTenUsers(context.Context, *TopTenParameter) (*TopTenUsersResponse, error)
+	GetAuthorStats(context.Context, *UserID) (*AuthorStatsResponse, error)
+	// BatchGetPostStats skips posts that are not visible to the caller.
+	BatchGetPostStats(context.Context, *BatchPostStatsRequest) (*BatchPostStatsResponse, error)
 	mustEmbedUnimplementedStatisticServiceServer()
 }
 
@@ -524,12 +702,30 @@ func (UnimplementedStatisticServiceServer) GetCommentsDynamic(context.Context, *
 func (UnimplementedStatisticServiceServer) GetLikesDynamic(context.Context, *PostID) (*DynamicListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetLikesDynamic not implemented")
 }
+func (UnimplementedStatisticServiceServer) GetUniqueViewersCount(context.Context, *PostID) (*CountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUniqueViewersCount not implemented")
+}
+func (UnimplementedStatisticServiceServer) GetUniqueLikersCount(context.Context, *PostID) (*CountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUniqueLikersCount not implemented")
+}
+func (UnimplementedStatisticServiceServer) GetUniqueViewersDynamic(context.Context, *PostID) (*DynamicListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUniqueViewersDynamic not implemented")
+}
+func (UnimplementedStatisticServiceServer) GetUniqueLikersDynamic(context.Context, *PostID) (*DynamicListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUniqueLikersDynamic not implemented")
+}
 func (UnimplementedStatisticServiceServer) GetTopTenPosts(context.Context, *TopTenParameter) (*TopTenPostsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTopTenPosts not implemented")
 }
 func (UnimplementedStatisticServiceServer) GetTopTenUsers(context.Context, *TopTenParameter) (*TopTenUsersResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTopTenUsers not implemented")
 }
+func (UnimplementedStatisticServiceServer) GetAuthorStats(context.Context, *UserID) (*AuthorStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuthorStats not implemented")
+}
+func (UnimplementedStatisticServiceServer) BatchGetPostStats(context.Context, *BatchPostStatsRequest) (*BatchPostStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetPostStats not implemented")
+}
 func (UnimplementedStatisticServiceServer) mustEmbedUnimplementedStatisticServiceServer() {}
 
 // UnsafeStatisticServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -651,6 +847,78 @@ func _StatisticService_GetLikesDynamic_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StatisticService_GetUniqueViewersCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PostID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatisticServiceServer).GetUniqueViewersCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/posts_service.StatisticService/GetUniqueViewersCount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatisticServiceServer).GetUniqueViewersCount(ctx, req.(*PostID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatisticService_GetUniqueLikersCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PostID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatisticServiceServer).GetUniqueLikersCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/posts_service.StatisticService/GetUniqueLikersCount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatisticServiceServer).GetUniqueLikersCount(ctx, req.(*PostID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatisticService_GetUniqueViewersDynamic_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PostID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatisticServiceServer).GetUniqueViewersDynamic(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/posts_service.StatisticService/GetUniqueViewersDynamic",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatisticServiceServer).GetUniqueViewersDynamic(ctx, req.(*PostID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatisticService_GetUniqueLikersDynamic_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PostID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatisticServiceServer).GetUniqueLikersDynamic(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/posts_service.StatisticService/GetUniqueLikersDynamic",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatisticServiceServer).GetUniqueLikersDynamic(ctx, req.(*PostID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _StatisticService_GetTopTenPosts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(TopTenParameter)
 	if err := dec(in); err != nil {
@@ -687,6 +955,42 @@ func _StatisticService_GetTopTenUsers_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StatisticService_GetAuthorStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatisticServiceServer).GetAuthorStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/posts_service.StatisticService/GetAuthorStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatisticServiceServer).GetAuthorStats(ctx, req.(*UserID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatisticService_BatchGetPostStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchPostStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatisticServiceServer).BatchGetPostStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/posts_service.StatisticService/BatchGetPostStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatisticServiceServer).BatchGetPostStats(ctx, req.(*BatchPostStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // StatisticService_ServiceDesc is the grpc.ServiceDesc for StatisticService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -718,6 +1022,22 @@ var StatisticService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetLikesDynamic",
 			Handler:    _StatisticService_GetLikesDynamic_Handler,
 		},
+		{
+			MethodName: "GetUniqueViewersCount",
+			Handler:    _StatisticService_GetUniqueViewersCount_Handler,
+		},
+		{
+			MethodName: "GetUniqueLikersCount",
+			Handler:    _StatisticService_GetUniqueLikersCount_Handler,
+		},
+		{
+			MethodName: "GetUniqueViewersDynamic",
+			Handler:    _StatisticService_GetUniqueViewersDynamic_Handler,
+		},
+		{
+			MethodName: "GetUniqueLikersDynamic",
+			Handler:    _StatisticService_GetUniqueLikersDynamic_Handler,
+		},
 		{
 			MethodName: "GetTopTenPosts",
 			Handler:    _StatisticService_GetTopTenPosts_Handler,
@@ -726,6 +1046,408 @@ var StatisticService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetTopTenUsers",
 			Handler:    _StatisticService_GetTopTenUsers_Handler,
 		},
+		{
+			MethodName: "GetAuthorStats",
+			Handler:    _StatisticService_GetAuthorStats_Handler,
+		},
+		{
+			MethodName: "BatchGetPostStats",
+			Handler:    _StatisticService_BatchGetPostStats_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "protos/soa.proto",
+}
+
+// UsersServiceClient is the client API for UsersService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type UsersServiceClient interface {
+	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*UserID, error)
+	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+	// GetUser returns the caller when user_id is not set.
+	GetUser(ctx context.Context, in *UserID, opts ...grpc.CallOption) (*UserResponse, error)
+	// BatchGetUsers skips unknown users, it is meant for other services and is not exposed over HTTP.
+	BatchGetUsers(ctx context.Context, in *BatchGetUsersRequest, opts ...grpc.CallOption) (*BatchGetUsersResponse, error)
+	// UpdateUser updates the caller, empty fields are kept.
+	UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*UserResponse, error)
+}
+
+type usersServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewUsersServiceClient(cc grpc.ClientConnInterface) UsersServiceClient {
+	return &usersServiceClient{cc}
+}
+
+func (c *usersServiceClient) Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*UserID, error) {
+	out := new(UserID)
+	err := c.cc.Invoke(ctx, "/posts_service.UsersService/Register", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersServiceClient) Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error) {
+	out := new(LoginResponse)
+	err := c.cc.Invoke(ctx, "/posts_service.UsersService/Login", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersServiceClient) GetUser(ctx context.Context, in *UserID, opts ...grpc.CallOption) (*UserResponse, error) {
+	out := new(UserResponse)
+	err := c.cc.Invoke(ctx, "/posts_service.UsersService/GetUser", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersServiceClient) BatchGetUsers(ctx context.Context, in *BatchGetUsersRequest, opts ...grpc.CallOption) (*BatchGetUsersResponse, error) {
+	out := new(BatchGetUsersResponse)
+	err := c.cc.Invoke(ctx, "/posts_service.UsersService/BatchGetUsers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersServiceClient) UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*UserResponse, error) {
+	out := new(UserResponse)
+	err := c.cc.Invoke(ctx, "/posts_service.UsersService/UpdateUser", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UsersServiceServer is the server API for UsersService service.
+// All implementations must embed UnimplementedUsersServiceServer
+// for forward compatibility
+type UsersServiceServer interface {
+	Register(context.Context, *RegisterRequest) (*UserID, error)
+	Login(context.Context, *LoginRequest) (*LoginResponse, error)
+	// GetUser returns the caller when user_id is not set.
+	GetUser(context.Context, *UserID) (*UserResponse, error)
+	// BatchGetUsers skips unknown users, it is meant for other services and is not exposed over HTTP.
+	BatchGetUsers(context.Context, *BatchGetUsersRequest) (*BatchGetUsersResponse, error)
+	// UpdateUser updates the caller, empty fields are kept.
+	UpdateUser(context.Context, *UpdateUserRequest) (*UserResponse, error)
+	mustEmbedUnimplementedUsersServiceServer()
+}
+
+// UnimplementedUsersServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedUsersServiceServer struct {
+}
+
+func (UnimplementedUsersServiceServer) Register(context.Context, *RegisterRequest) (*UserID, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
+}
+func (UnimplementedUsersServiceServer) Login(context.Context, *LoginRequest) (*LoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Login not implemented")
+}
+func (UnimplementedUsersServiceServer) GetUser(context.Context, *UserID) (*UserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
+}
+func (UnimplementedUsersServiceServer) BatchGetUsers(context.Context, *BatchGetUsersRequest) (*BatchGetUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetUsers not implemented")
+}
+func (UnimplementedUsersServiceServer) UpdateUser(context.Context, *UpdateUserRequest) (*UserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateUser not implemented")
+}
+func (UnimplementedUsersServiceServer) mustEmbedUnimplementedUsersServiceServer() {}
+
+// UnsafeUsersServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to UsersServiceServer will
+// result in compilation errors.
+type UnsafeUsersServiceServer interface {
+	mustEmbedUnimplementedUsersServiceServer()
+}
+
+func RegisterUsersServiceServer(s grpc.ServiceRegistrar, srv UsersServiceServer) {
+	s.RegisterService(&UsersService_ServiceDesc, srv)
+}
+
+func _UsersService_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServiceServer).Register(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/posts_service.UsersService/Register",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServiceServer).Register(ctx, req.(*RegisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UsersService_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServiceServer).Login(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/posts_service.UsersService/Login",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServiceServer).Login(ctx, req.(*LoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UsersService_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServiceServer).GetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/posts_service.UsersService/GetUser",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServiceServer).GetUser(ctx, req.(*UserID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UsersService_BatchGetUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServiceServer).BatchGetUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/posts_service.UsersService/BatchGetUsers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServiceServer).BatchGetUsers(ctx, req.(*BatchGetUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UsersService_UpdateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServiceServer).UpdateUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/posts_service.UsersService/UpdateUser",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServiceServer).UpdateUser(ctx, req.(*UpdateUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// UsersService_ServiceDesc is the grpc.ServiceDesc for UsersService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var UsersService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "posts_service.UsersService",
+	HandlerType: (*UsersServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Register",
+			Handler:    _UsersService_Register_Handler,
+		},
+		{
+			MethodName: "Login",
+			Handler:    _UsersService_Login_Handler,
+		},
+		{
+			MethodName: "GetUser",
+			Handler:    _UsersService_GetUser_Handler,
+		},
+		{
+			MethodName: "BatchGetUsers",
+			Handler:    _UsersService_BatchGetUsers_Handler,
+		},
+		{
+			MethodName: "UpdateUser",
+			Handler:    _UsersService_UpdateUser_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "protos/soa.proto",
+}
+
+// NotificationsServiceClient is the client API for NotificationsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type NotificationsServiceClient interface {
+	GetNotifications(ctx context.Context, in *PaginatedListRequest, opts ...grpc.CallOption) (*ListNotificationsResponse, error)
+	MarkNotificationsRead(ctx context.Context, in *MarkNotificationsReadRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	GetUnreadNotificationsCount(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*CountResponse, error)
+}
+
+type notificationsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNotificationsServiceClient(cc grpc.ClientConnInterface) NotificationsServiceClient {
+	return &notificationsServiceClient{cc}
+}
+
+func (c *notificationsServiceClient) GetNotifications(ctx context.Context, in *PaginatedListRequest, opts ...grpc.CallOption) (*ListNotificationsResponse, error) {
+	out := new(ListNotificationsResponse)
+	err := c.cc.Invoke(ctx, "/posts_service.NotificationsService/GetNotifications", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationsServiceClient) MarkNotificationsRead(ctx context.Context, in *MarkNotificationsReadRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/posts_service.NotificationsService/MarkNotificationsRead", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationsServiceClient) GetUnreadNotificationsCount(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*CountResponse, error) {
+	out := new(CountResponse)
+	err := c.cc.Invoke(ctx, "/posts_service.NotificationsService/GetUnreadNotificationsCount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// NotificationsServiceServer is the server API for NotificationsService service.
+// All implementations must embed UnimplementedNotificationsServiceServer
+// for forward compatibility
+type NotificationsServiceServer interface {
+	GetNotifications(context.Context, *PaginatedListRequest) (*ListNotificationsResponse, error)
+	MarkNotificationsRead(context.Context, *MarkNotificationsReadRequest) (*empty.Empty, error)
+	GetUnreadNotificationsCount(context.Context, *empty.Empty) (*CountResponse, error)
+	mustEmbedUnimplementedNotificationsServiceServer()
+}
+
+// UnimplementedNotificationsServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedNotificationsServiceServer struct {
+}
+
+func (UnimplementedNotificationsServiceServer) GetNotifications(context.Context, *PaginatedListRequest) (*ListNotificationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNotifications not implemented")
+}
+func (UnimplementedNotificationsServiceServer) MarkNotificationsRead(context.Context, *MarkNotificationsReadRequest) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MarkNotificationsRead not implemented")
+}
+func (UnimplementedNotificationsServiceServer) GetUnreadNotificationsCount(context.Context, *empty.Empty) (*CountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUnreadNotificationsCount not implemented")
+}
+func (UnimplementedNotificationsServiceServer) mustEmbedUnimplementedNotificationsServiceServer() {}
+
+// UnsafeNotificationsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NotificationsServiceServer will
+// result in compilation errors.
+type UnsafeNotificationsServiceServer interface {
+	mustEmbedUnimplementedNotificationsServiceServer()
+}
+
+func RegisterNotificationsServiceServer(s grpc.ServiceRegistrar, srv NotificationsServiceServer) {
+	s.RegisterService(&NotificationsService_ServiceDesc, srv)
+}
+
+func _NotificationsService_GetNotifications_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PaginatedListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationsServiceServer).GetNotifications(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/posts_service.NotificationsService/GetNotifications",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationsServiceServer).GetNotifications(ctx, req.(*PaginatedListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationsService_MarkNotificationsRead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MarkNotificationsReadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationsServiceServer).MarkNotificationsRead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/posts_service.NotificationsService/MarkNotificationsRead",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationsServiceServer).MarkNotificationsRead(ctx, req.(*MarkNotificationsReadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationsService_GetUnreadNotificationsCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationsServiceServer).GetUnreadNotificationsCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/posts_service.NotificationsService/GetUnreadNotificationsCount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationsServiceServer).GetUnreadNotificationsCount(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// NotificationsService_ServiceDesc is the grpc.ServiceDesc for NotificationsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var NotificationsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "posts_service.NotificationsService",
+	HandlerType: (*NotificationsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetNotifications",
+			Handler:    _NotificationsService_GetNotifications_Handler,
+		},
+		{
+			MethodName: "MarkNotificationsRead",
+			Handler:    _NotificationsService_MarkNotificationsRead_Handler,
+		},
+		{
+			MethodName: "GetUnreadNotificationsCount",
+			Handler:    _NotificationsService_GetUnreadNotificationsCount_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "protos/soa.proto",
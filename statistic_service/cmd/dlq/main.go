@@ -0,0 +1,127 @@
+// Command dlq inspects and replays statistic events that were sent to the dead letter topic.
+//
+//	dlq inspect [-limit N] > letters.jsonl
+//	dlq replay -f letters.jsonl
+//
+// inspect prints dead letters as JSON lines without committing anything. The lines can be
+// fixed by hand (the value field holds the original message, protobuf events are shown as
+// JSON with "encoding": "protojson") and replayed back to their original topics with replay.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/config"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/kafka"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/models"
+	"github.com/joho/godotenv"
+	"os"
+	"os/signal"
+)
+
+func init() {
+	if err := godotenv.Load(); err != nil {
+		logger.Logger.Error("env file is not found")
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	cfg, err := config.NewConfig()
+	if err != nil {
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	switch os.Args[1] {
+	case "inspect":
+		err = inspect(ctx, cfg, os.Args[2:])
+	case "replay":
+		err = replay(ctx, cfg, os.Args[2:])
+	default:
+		usage()
+	}
+
+	if err != nil {
+		logger.Logger.Error("dlq command error", "command", os.Args[1], "error", err.Error())
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: dlq inspect [-limit N] | dlq replay -f FILE")
+	os.Exit(2)
+}
+
+func inspect(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	limit := fs.Int("limit", 0, "maximum number of dead letters to print, 0 prints all")
+	_ = fs.Parse(args)
+
+	letters, err := kafka.ReadDeadLetters(ctx, cfg, *limit)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	for _, d := range letters {
+		if err = enc.Encode(d); err != nil {
+			return err
+		}
+	}
+
+	logger.Logger.Info("dead letters inspected", "topic", cfg.DeadLetterTopic, "count", len(letters))
+
+	return nil
+}
+
+func replay(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	file := fs.String("f", "", "JSON lines file produced by inspect")
+	_ = fs.Parse(args)
+
+	if *file == "" {
+		usage()
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var letters []*models.DeadLetter
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var d models.DeadLetter
+		if err = json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			return err
+		}
+		letters = append(letters, &d)
+	}
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+
+	if err = kafka.Replay(ctx, cfg, letters); err != nil {
+		return err
+	}
+
+	logger.Logger.Info("dead letters replayed", "count", len(letters))
+
+	return nil
+}
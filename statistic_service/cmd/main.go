@@ -3,10 +3,15 @@ package main
 import (
 	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/application"
 	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/config"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/clients"
 	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/db"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/health"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/kafka"
 	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/metrics"
 	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/repository"
 	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/repository/txs"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/tracing"
 	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/server"
 	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/service"
 	"github.com/joho/godotenv"
@@ -23,6 +28,7 @@ func main() {
 	addOpts := fx.Options(
 		fx.Provide(
 			config.NewConfig,
+			tracing.NewTracerProvider,
 			db.InitDb,
 			repository.NewRepository,
 			func(r *repository.Repository) service.StatisticRepository {
@@ -32,14 +38,24 @@ func main() {
 			func(t *txs.TxBeginner) service.Transactor {
 				return t
 			},
+			clients.NewPostsClient,
+			func(c *clients.PostsClient) service.PostsClient {
+				return c
+			},
 			service.NewService,
 			func(s *service.Service) application.StatisticService {
 				return s
 			},
+			func(s *service.Service) kafka.EventsHandler {
+				return s
+			},
+			kafka.NewConsumer,
 			application.NewStatisticServiceApp,
 			server.NewServer,
 		),
-		fx.Invoke(server.RunServer),
+		metrics.Module,
+		health.Module,
+		fx.Invoke(server.RunServer, kafka.RunConsumer),
 	)
 
 	fx.New(addOpts).Run()
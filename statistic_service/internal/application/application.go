@@ -2,10 +2,19 @@ package application
 
 import (
 	"context"
+	"errors"
 	pb "github.com/grigorovskiiy/soa-hse/protos"
+	statErrors "github.com/grigorovskiiy/soa-hse/statistic_service/internal/errors"
 	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/logger"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strconv"
 )
 
+// maxBatchPostIDs limits BatchGetPostStats, the posts are checked with one GetPostsMeta request.
+const maxBatchPostIDs = 100
+
 type StatisticService interface {
 	GetViewsCount(ctx context.Context, pb *pb.PostID) (*pb.CountResponse, error)
 	GetCommentsCount(ctx context.Context, pb *pb.PostID) (*pb.CountResponse, error)
@@ -13,8 +22,16 @@ type StatisticService interface {
 	GetViewsDynamic(ctx context.Context, pb *pb.PostID) (*pb.DynamicListResponse, error)
 	GetCommentsDynamic(ctx context.Context, pb *pb.PostID) (*pb.DynamicListResponse, error)
 	GetLikesDynamic(ctx context.Context, pb *pb.PostID) (*pb.DynamicListResponse, error)
-	GetTopTenPosts(ctx context.Context, in *pb.TopTenParameter) (*pb.TopTenPostsResponse, error)
+	GetUniqueViewersCount(ctx context.Context, pb *pb.PostID) (*pb.CountResponse, error)
+	GetUniqueLikersCount(ctx context.Context, pb *pb.PostID) (*pb.CountResponse, error)
+	GetUniqueViewersDynamic(ctx context.Context, pb *pb.PostID) (*pb.DynamicListResponse, error)
+	GetUniqueLikersDynamic(ctx context.Context, pb *pb.PostID) (*pb.DynamicListResponse, error)
+	GetTopTenPosts(ctx context.Context, in *pb.TopTenParameter, userID int32) (*pb.TopTenPostsResponse, error)
 	GetTopTenUsers(ctx context.Context, in *pb.TopTenParameter) (*pb.TopTenUsersResponse, error)
+	GetAuthorStats(ctx context.Context, in *pb.UserID) (*pb.AuthorStatsResponse, error)
+	BatchGetPostStats(ctx context.Context, in *pb.BatchPostStatsRequest) (*pb.BatchPostStatsResponse, error)
+	CheckPostAccess(ctx context.Context, postID int32, userID int32) error
+	VisiblePosts(ctx context.Context, postIDs []int32, userID int32) ([]int32, error)
 }
 
 type StatisticServiceApp struct {
@@ -27,9 +44,14 @@ func NewStatisticServiceApp(StatisticService StatisticService) *StatisticService
 }
 
 func (s *StatisticServiceApp) GetViewsCount(ctx context.Context, pb *pb.PostID) (*pb.CountResponse, error) {
-	logger := logger.Logger.With("method", "GetViewsCount")
+	logger := logger.FromContext(ctx).With("method", "GetViewsCount")
 	logger.Info("statistic grpc request started")
 
+	if err := s.checkPostAccess(ctx, pb.PostId); err != nil {
+		logger.Error("post access check error", "error", err.Error())
+		return nil, err
+	}
+
 	count, err := s.StatisticService.GetViewsCount(ctx, pb)
 	if err != nil {
 		logger.Error("error getting views count", "error", err.Error())
@@ -42,9 +64,14 @@ func (s *StatisticServiceApp) GetViewsCount(ctx context.Context, pb *pb.PostID)
 }
 
 func (s *StatisticServiceApp) GetCommentsCount(ctx context.Context, pb *pb.PostID) (*pb.CountResponse, error) {
-	logger := logger.Logger.With("method", "GetCommentsCount")
+	logger := logger.FromContext(ctx).With("method", "GetCommentsCount")
 	logger.Info("statistic grpc request started")
 
+	if err := s.checkPostAccess(ctx, pb.PostId); err != nil {
+		logger.Error("post access check error", "error", err.Error())
+		return nil, err
+	}
+
 	count, err := s.StatisticService.GetCommentsCount(ctx, pb)
 	if err != nil {
 		logger.Error("error getting comments count", "error", err.Error())
@@ -57,9 +84,14 @@ func (s *StatisticServiceApp) GetCommentsCount(ctx context.Context, pb *pb.PostI
 }
 
 func (s *StatisticServiceApp) GetLikesCount(ctx context.Context, pb *pb.PostID) (*pb.CountResponse, error) {
-	logger := logger.Logger.With("method", "GetLikesCount")
+	logger := logger.FromContext(ctx).With("method", "GetLikesCount")
 	logger.Info("statistic grpc request started")
 
+	if err := s.checkPostAccess(ctx, pb.PostId); err != nil {
+		logger.Error("post access check error", "error", err.Error())
+		return nil, err
+	}
+
 	count, err := s.StatisticService.GetLikesCount(ctx, pb)
 	if err != nil {
 		logger.Error("error getting likes count", "error", err.Error())
@@ -72,9 +104,14 @@ func (s *StatisticServiceApp) GetLikesCount(ctx context.Context, pb *pb.PostID)
 }
 
 func (s *StatisticServiceApp) GetViewsDynamic(ctx context.Context, pb *pb.PostID) (*pb.DynamicListResponse, error) {
-	logger := logger.Logger.With("method", "GetViewsDynamic")
+	logger := logger.FromContext(ctx).With("method", "GetViewsDynamic")
 	logger.Info("statistic grpc request started")
 
+	if err := s.checkPostAccess(ctx, pb.PostId); err != nil {
+		logger.Error("post access check error", "error", err.Error())
+		return nil, err
+	}
+
 	dynamic, err := s.StatisticService.GetViewsDynamic(ctx, pb)
 	if err != nil {
 		logger.Error("error getting views dynamic", "error", err.Error())
@@ -87,10 +124,15 @@ func (s *StatisticServiceApp) GetViewsDynamic(ctx context.Context, pb *pb.PostID
 }
 
 func (s *StatisticServiceApp) GetCommentsDynamic(ctx context.Context, pb *pb.PostID) (*pb.DynamicListResponse, error) {
-	logger := logger.Logger.With("method", "GetCommentsDynamic")
+	logger := logger.FromContext(ctx).With("method", "GetCommentsDynamic")
 	logger.Info("statistic grpc request started")
 
-	dynamic, err := s.StatisticService.GetViewsDynamic(ctx, pb)
+	if err := s.checkPostAccess(ctx, pb.PostId); err != nil {
+		logger.Error("post access check error", "error", err.Error())
+		return nil, err
+	}
+
+	dynamic, err := s.StatisticService.GetCommentsDynamic(ctx, pb)
 	if err != nil {
 		logger.Error("error getting comments dynamic", "error", err.Error())
 		return nil, err
@@ -102,9 +144,14 @@ func (s *StatisticServiceApp) GetCommentsDynamic(ctx context.Context, pb *pb.Pos
 }
 
 func (s *StatisticServiceApp) GetLikesDynamic(ctx context.Context, pb *pb.PostID) (*pb.DynamicListResponse, error) {
-	logger := logger.Logger.With("method", "GetLikesDynamic")
+	logger := logger.FromContext(ctx).With("method", "GetLikesDynamic")
 	logger.Info("statistic grpc request started")
 
+	if err := s.checkPostAccess(ctx, pb.PostId); err != nil {
+		logger.Error("post access check error", "error", err.Error())
+		return nil, err
+	}
+
 	dynamic, err := s.StatisticService.GetLikesDynamic(ctx, pb)
 	if err != nil {
 		logger.Error("error getting likes dynamic", "error", err.Error())
@@ -116,11 +163,97 @@ func (s *StatisticServiceApp) GetLikesDynamic(ctx context.Context, pb *pb.PostID
 	return dynamic, nil
 }
 
+func (s *StatisticServiceApp) GetUniqueViewersCount(ctx context.Context, pb *pb.PostID) (*pb.CountResponse, error) {
+	logger := logger.FromContext(ctx).With("method", "GetUniqueViewersCount")
+	logger.Info("statistic grpc request started")
+
+	if err := s.checkPostAccess(ctx, pb.PostId); err != nil {
+		logger.Error("post access check error", "error", err.Error())
+		return nil, err
+	}
+
+	count, err := s.StatisticService.GetUniqueViewersCount(ctx, pb)
+	if err != nil {
+		logger.Error("error getting unique viewers count", "error", err.Error())
+		return nil, err
+	}
+
+	logger.Info("statistic grpc request completed")
+
+	return count, nil
+}
+
+func (s *StatisticServiceApp) GetUniqueLikersCount(ctx context.Context, pb *pb.PostID) (*pb.CountResponse, error) {
+	logger := logger.FromContext(ctx).With("method", "GetUniqueLikersCount")
+	logger.Info("statistic grpc request started")
+
+	if err := s.checkPostAccess(ctx, pb.PostId); err != nil {
+		logger.Error("post access check error", "error", err.Error())
+		return nil, err
+	}
+
+	count, err := s.StatisticService.GetUniqueLikersCount(ctx, pb)
+	if err != nil {
+		logger.Error("error getting unique likers count", "error", err.Error())
+		return nil, err
+	}
+
+	logger.Info("statistic grpc request completed")
+
+	return count, nil
+}
+
+func (s *StatisticServiceApp) GetUniqueViewersDynamic(ctx context.Context, pb *pb.PostID) (*pb.DynamicListResponse, error) {
+	logger := logger.FromContext(ctx).With("method", "GetUniqueViewersDynamic")
+	logger.Info("statistic grpc request started")
+
+	if err := s.checkPostAccess(ctx, pb.PostId); err != nil {
+		logger.Error("post access check error", "error", err.Error())
+		return nil, err
+	}
+
+	dynamic, err := s.StatisticService.GetUniqueViewersDynamic(ctx, pb)
+	if err != nil {
+		logger.Error("error getting unique viewers dynamic", "error", err.Error())
+		return nil, err
+	}
+
+	logger.Info("statistic grpc request completed")
+
+	return dynamic, nil
+}
+
+func (s *StatisticServiceApp) GetUniqueLikersDynamic(ctx context.Context, pb *pb.PostID) (*pb.DynamicListResponse, error) {
+	logger := logger.FromContext(ctx).With("method", "GetUniqueLikersDynamic")
+	logger.Info("statistic grpc request started")
+
+	if err := s.checkPostAccess(ctx, pb.PostId); err != nil {
+		logger.Error("post access check error", "error", err.Error())
+		return nil, err
+	}
+
+	dynamic, err := s.StatisticService.GetUniqueLikersDynamic(ctx, pb)
+	if err != nil {
+		logger.Error("error getting unique likers dynamic", "error", err.Error())
+		return nil, err
+	}
+
+	logger.Info("statistic grpc request completed")
+
+	return dynamic, nil
+}
+
 func (s *StatisticServiceApp) GetTopTenPosts(ctx context.Context, pb *pb.TopTenParameter) (*pb.TopTenPostsResponse, error) {
-	logger := logger.Logger.With("method", "GetTopTenPosts")
+	logger := logger.FromContext(ctx).With("method", "GetTopTenPosts")
 	logger.Info("statistic grpc request started")
 
-	posts, err := s.StatisticService.GetTopTenPosts(ctx, pb)
+	userID, err := GetUserID(ctx)
+	if err != nil {
+		logger.Error("error getting userID from ctx", "error", err.Error())
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	posts, err := s.StatisticService.GetTopTenPosts(ctx, pb, userID)
 	if err != nil {
 		logger.Error("error getting top ten posts", "error", err.Error())
 		return nil, err
@@ -132,7 +265,7 @@ func (s *StatisticServiceApp) GetTopTenPosts(ctx context.Context, pb *pb.TopTenP
 }
 
 func (s *StatisticServiceApp) GetTopTenUsers(ctx context.Context, pb *pb.TopTenParameter) (*pb.TopTenUsersResponse, error) {
-	logger := logger.Logger.With("method", "GetTopTenPosts")
+	logger := logger.FromContext(ctx).With("method", "GetTopTenPosts")
 	logger.Info("statistic grpc request started")
 
 	users, err := s.StatisticService.GetTopTenUsers(ctx, pb)
@@ -145,3 +278,126 @@ func (s *StatisticServiceApp) GetTopTenUsers(ctx context.Context, pb *pb.TopTenP
 
 	return users, nil
 }
+
+func (s *StatisticServiceApp) GetAuthorStats(ctx context.Context, pb *pb.UserID) (*pb.AuthorStatsResponse, error) {
+	logger := logger.FromContext(ctx).With("method", "GetAuthorStats")
+	logger.Info("statistic grpc request started")
+
+	userID, err := GetUserID(ctx)
+	if err != nil {
+		logger.Error("error getting userID from ctx", "error", err.Error())
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if userID != pb.UserId {
+		logger.Error("author stats access denied", "user_id", userID, "author_id", pb.UserId)
+		return nil, status.Error(codes.PermissionDenied, statErrors.AuthorStatsAccessDeniedError{}.Error())
+	}
+
+	stats, err := s.StatisticService.GetAuthorStats(ctx, pb)
+	if err != nil {
+		logger.Error("error getting author stats", "error", err.Error())
+		return nil, err
+	}
+
+	logger.Info("statistic grpc request completed")
+
+	return stats, nil
+}
+
+func (s *StatisticServiceApp) BatchGetPostStats(ctx context.Context, pb *pb.BatchPostStatsRequest) (*pb.BatchPostStatsResponse, error) {
+	logger := logger.FromContext(ctx).With("method", "BatchGetPostStats")
+	logger.Info("statistic grpc request started")
+
+	if len(pb.PostIds) > maxBatchPostIDs {
+		logger.Error("too many post ids", "count", len(pb.PostIds))
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d post ids are allowed", maxBatchPostIDs)
+	}
+
+	posts, err := s.visiblePosts(ctx, toPostIDs(pb.PostIds))
+	if err != nil {
+		logger.Error("post access check error", "error", err.Error())
+		return nil, err
+	}
+
+	stats, err := s.StatisticService.BatchGetPostStats(ctx, newBatchPostStatsRequest(posts))
+	if err != nil {
+		logger.Error("error getting posts stats", "error", err.Error())
+		return nil, err
+	}
+
+	logger.Info("statistic grpc request completed")
+
+	return stats, nil
+}
+
+func (s *StatisticServiceApp) checkPostAccess(ctx context.Context, postID int32) error {
+	userID, err := GetUserID(ctx)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	err = s.StatisticService.CheckPostAccess(ctx, postID, userID)
+	switch {
+	case errors.As(err, &statErrors.PostAccessDeniedError{}):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.As(err, &statErrors.PostNotFoundError{}):
+		return status.Error(codes.NotFound, err.Error())
+	}
+
+	return err
+}
+
+// visiblePosts drops private posts of other users and posts that were deleted.
+func (s *StatisticServiceApp) visiblePosts(ctx context.Context, posts []*pb.PostID) ([]*pb.PostID, error) {
+	userID, err := GetUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	ids := make([]int32, len(posts))
+	for i, post := range posts {
+		ids[i] = post.PostId
+	}
+
+	visible, err := s.StatisticService.VisiblePosts(ctx, ids, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPostIDs(visible), nil
+}
+
+func GetUserID(ctx context.Context) (int32, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0, errors.New("no metadata from incoming context")
+	}
+	values := md.Get("user_id")
+	if len(values) != 1 {
+		return 0, errors.New("user_id must be passed once")
+	}
+	userId, err := strconv.Atoi(values[0])
+	if err != nil {
+		return 0, err
+	}
+
+	return int32(userId), nil
+}
+
+func toPostIDs(ids []int32) []*pb.PostID {
+	posts := make([]*pb.PostID, len(ids))
+	for i, id := range ids {
+		posts[i] = &pb.PostID{PostId: id}
+	}
+
+	return posts
+}
+
+func newBatchPostStatsRequest(posts []*pb.PostID) *pb.BatchPostStatsRequest {
+	req := &pb.BatchPostStatsRequest{PostIds: make([]int32, len(posts))}
+	for i, post := range posts {
+		req.PostIds[i] = post.PostId
+	}
+
+	return req
+}
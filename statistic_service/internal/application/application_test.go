@@ -0,0 +1,148 @@
+package application
+
+import (
+	"context"
+	pb "github.com/grigorovskiiy/soa-hse/protos"
+	statErrors "github.com/grigorovskiiy/soa-hse/statistic_service/internal/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+const privatePostID = 13
+
+// fakeStatisticService answers every metric with its own count, so that a handler calling
+// the method of another metric is caught by the value it returns.
+type fakeStatisticService struct {
+	StatisticService
+}
+
+func (fakeStatisticService) dynamic(count int32) *pb.DynamicListResponse {
+	return &pb.DynamicListResponse{Dynamic: []*pb.DynamicResponse{{Count: &pb.CountResponse{Count: count}}}}
+}
+
+func (fakeStatisticService) GetViewsCount(context.Context, *pb.PostID) (*pb.CountResponse, error) {
+	return &pb.CountResponse{Count: 1}, nil
+}
+
+func (fakeStatisticService) GetLikesCount(context.Context, *pb.PostID) (*pb.CountResponse, error) {
+	return &pb.CountResponse{Count: 2}, nil
+}
+
+func (fakeStatisticService) GetCommentsCount(context.Context, *pb.PostID) (*pb.CountResponse, error) {
+	return &pb.CountResponse{Count: 3}, nil
+}
+
+func (fakeStatisticService) GetUniqueViewersCount(context.Context, *pb.PostID) (*pb.CountResponse, error) {
+	return &pb.CountResponse{Count: 4}, nil
+}
+
+func (fakeStatisticService) GetUniqueLikersCount(context.Context, *pb.PostID) (*pb.CountResponse, error) {
+	return &pb.CountResponse{Count: 5}, nil
+}
+
+func (s fakeStatisticService) GetViewsDynamic(context.Context, *pb.PostID) (*pb.DynamicListResponse, error) {
+	return s.dynamic(6), nil
+}
+
+func (s fakeStatisticService) GetLikesDynamic(context.Context, *pb.PostID) (*pb.DynamicListResponse, error) {
+	return s.dynamic(7), nil
+}
+
+func (s fakeStatisticService) GetCommentsDynamic(context.Context, *pb.PostID) (*pb.DynamicListResponse, error) {
+	return s.dynamic(8), nil
+}
+
+func (s fakeStatisticService) GetUniqueViewersDynamic(context.Context, *pb.PostID) (*pb.DynamicListResponse, error) {
+	return s.dynamic(9), nil
+}
+
+func (s fakeStatisticService) GetUniqueLikersDynamic(context.Context, *pb.PostID) (*pb.DynamicListResponse, error) {
+	return s.dynamic(10), nil
+}
+
+func (fakeStatisticService) CheckPostAccess(_ context.Context, postID int32, _ int32) error {
+	if postID == privatePostID {
+		return statErrors.PostAccessDeniedError{}
+	}
+
+	return nil
+}
+
+type postMetric struct {
+	name  string
+	want  int32
+	count func(context.Context, *pb.PostID) (*pb.CountResponse, error)
+	dyn   func(context.Context, *pb.PostID) (*pb.DynamicListResponse, error)
+}
+
+func postMetrics(app *StatisticServiceApp) []postMetric {
+	return []postMetric{
+		{name: "GetViewsCount", want: 1, count: app.GetViewsCount},
+		{name: "GetLikesCount", want: 2, count: app.GetLikesCount},
+		{name: "GetCommentsCount", want: 3, count: app.GetCommentsCount},
+		{name: "GetUniqueViewersCount", want: 4, count: app.GetUniqueViewersCount},
+		{name: "GetUniqueLikersCount", want: 5, count: app.GetUniqueLikersCount},
+		{name: "GetViewsDynamic", want: 6, dyn: app.GetViewsDynamic},
+		{name: "GetLikesDynamic", want: 7, dyn: app.GetLikesDynamic},
+		{name: "GetCommentsDynamic", want: 8, dyn: app.GetCommentsDynamic},
+		{name: "GetUniqueViewersDynamic", want: 9, dyn: app.GetUniqueViewersDynamic},
+		{name: "GetUniqueLikersDynamic", want: 10, dyn: app.GetUniqueLikersDynamic},
+	}
+}
+
+// call returns the count of a count metric, or the count of the only day of a dynamic one.
+func (m postMetric) call(ctx context.Context, postID int32) (int32, error) {
+	if m.count != nil {
+		res, err := m.count(ctx, &pb.PostID{PostId: postID})
+		if err != nil {
+			return 0, err
+		}
+		return res.Count, nil
+	}
+
+	res, err := m.dyn(ctx, &pb.PostID{PostId: postID})
+	if err != nil {
+		return 0, err
+	}
+	if len(res.Dynamic) != 1 {
+		return 0, status.Errorf(codes.Internal, "%d days, want 1", len(res.Dynamic))
+	}
+	return res.Dynamic[0].Count.Count, nil
+}
+
+func userContext() context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("user_id", "1"))
+}
+
+func TestPostMetricsReturnTheirOwnData(t *testing.T) {
+	app := NewStatisticServiceApp(fakeStatisticService{})
+
+	for _, m := range postMetrics(app) {
+		t.Run(m.name, func(t *testing.T) {
+			got, err := m.call(userContext(), 42)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != m.want {
+				t.Errorf("count %d, want %d", got, m.want)
+			}
+		})
+	}
+}
+
+func TestPostMetricsCheckAccess(t *testing.T) {
+	app := NewStatisticServiceApp(fakeStatisticService{})
+
+	for _, m := range postMetrics(app) {
+		t.Run(m.name, func(t *testing.T) {
+			if _, err := m.call(userContext(), privatePostID); status.Code(err) != codes.PermissionDenied {
+				t.Errorf("private post: %v, want PermissionDenied", err)
+			}
+			if _, err := m.call(context.Background(), 42); status.Code(err) != codes.Unauthenticated {
+				t.Errorf("no user: %v, want Unauthenticated", err)
+			}
+		})
+	}
+}
@@ -3,11 +3,17 @@ package config
 import (
 	"github.com/caarlos0/env/v8"
 	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/logger"
+	"time"
 )
 
 type Config struct {
 	ClickHouseConfig
+	KafkaConfig
 	StatisticServiceServerConfig
+	PostsServiceConfig
+	TracingConfig
+	MetricsConfig
+	HealthConfig
 }
 
 type ClickHouseConfig struct {
@@ -18,11 +24,49 @@ type ClickHouseConfig struct {
 	ClickHouseHost     string `env:"CLICKHOUSE_HOST" envDefault:"clickhouse"`
 }
 
+type KafkaConfig struct {
+	Brokers         []string      `env:"KAFKA_BROKERS" envSeparator:"," envDefault:"kafka:19092"`
+	CommentsTopic   string        `env:"KAFKA_COMMENTS_TOPIC" envDefault:"comments.topic"`
+	LikesTopic      string        `env:"KAFKA_LIKES_TOPIC" envDefault:"likes.topic"`
+	ViewsTopic      string        `env:"KAFKA_VIEWS_TOPIC" envDefault:"views.topic"`
+	DeadLetterTopic string        `env:"KAFKA_DEAD_LETTER_TOPIC" envDefault:"statistic.dlq.topic"`
+	ConsumerGroup   string        `env:"KAFKA_CONSUMER_GROUP" envDefault:"statistic-service"`
+	BatchSize       int           `env:"KAFKA_BATCH_SIZE" envDefault:"1000"`
+	FlushInterval   time.Duration `env:"KAFKA_FLUSH_INTERVAL" envDefault:"1s"`
+	RetryBackoff    time.Duration `env:"KAFKA_RETRY_BACKOFF" envDefault:"1s"`
+}
+
 type StatisticServiceServerConfig struct {
 	StatisticServicePort string `env:"STATISTIC_SERVICE_PORT" envDefault:":50052"`
 	StatisticServiceHost string `env:"STATISTIC_SERVICE_HOST" envDefault:"statistic-service"`
 }
 
+type PostsServiceConfig struct {
+	PostsServicePort string        `env:"POST_SERVICE_PORT" envDefault:":50051"`
+	PostsServiceHost string        `env:"POST_SERVICE_HOST" envDefault:"posts-service"`
+	PostMetaCacheTTL time.Duration `env:"POST_META_CACHE_TTL" envDefault:"30s"`
+}
+
+// TracingConfig selects where spans are exported: "otlp" sends them to TracingOTLPEndpoint,
+// "stdout" prints them and "none" only propagates the trace context.
+type TracingConfig struct {
+	TracingExporter     string  `env:"TRACING_EXPORTER" envDefault:"otlp"`
+	TracingOTLPEndpoint string  `env:"TRACING_OTLP_ENDPOINT" envDefault:"jaeger:4317"`
+	TracingSampleRatio  float64 `env:"TRACING_SAMPLE_RATIO" envDefault:"1"`
+}
+
+// MetricsConfig is the address of the /metrics endpoint, it is served apart from the API.
+type MetricsConfig struct {
+	MetricsPort string `env:"METRICS_PORT" envDefault:":9090"`
+}
+
+// HealthConfig configures the readiness checks, the gRPC health status is refreshed
+// every HealthCheckInterval.
+type HealthConfig struct {
+	HealthCheckInterval time.Duration `env:"HEALTH_CHECK_INTERVAL" envDefault:"10s"`
+	HealthCheckTimeout  time.Duration `env:"HEALTH_CHECK_TIMEOUT" envDefault:"2s"`
+}
+
 func NewConfig() (*Config, error) {
 	cfg := Config{}
 
@@ -6,3 +6,40 @@ type InvalidTopParameterError struct {
 func (e InvalidTopParameterError) Error() string {
 	return "invalid top parameter"
 }
+
+type InvalidEventError struct {
+	Reason string
+}
+
+func (e InvalidEventError) Error() string {
+	return "invalid event: " + e.Reason
+}
+
+type PostNotFoundError struct {
+}
+
+func (e PostNotFoundError) Error() string {
+	return "post not found"
+}
+
+type PostAccessDeniedError struct {
+}
+
+func (e PostAccessDeniedError) Error() string {
+	return "statistics of a private post are available only to its author"
+}
+
+type AuthorStatsAccessDeniedError struct {
+}
+
+func (e AuthorStatsAccessDeniedError) Error() string {
+	return "author statistics are available only to the author"
+}
+
+type UnknownTracingExporterError struct {
+	Exporter string
+}
+
+func (e UnknownTracingExporterError) Error() string {
+	return "unknown tracing exporter: " + e.Exporter
+}
@@ -0,0 +1,168 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	pb "github.com/grigorovskiiy/soa-hse/protos"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/config"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/errors"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/logger"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"sync"
+	"time"
+)
+
+type postMetaEntry struct {
+	meta      *pb.PostMetaResponse
+	expiresAt time.Time
+}
+
+// PostsClient asks posts_service who owns a post and whether it is private. Answers are
+// cached for PostMetaCacheTTL, so a visibility change takes up to that long to apply. Expired
+// answers are swept every PostMetaCacheTTL, a zero TTL turns the cache off.
+type PostsClient struct {
+	client pb.PostsServiceClient
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	cache map[int32]postMetaEntry
+}
+
+// requestIDInterceptor passes the ID of the request on to posts_service in the metadata.
+func requestIDInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if id := logger.RequestID(ctx); id != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, logger.RequestIDKey, id)
+	}
+
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func NewPostsClient(lc fx.Lifecycle, cfg *config.Config, tp trace.TracerProvider) (*PostsClient, error) {
+	conn, err := grpc.NewClient(fmt.Sprintf("%s%s", cfg.PostsServiceHost, cfg.PostsServicePort),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler(otelgrpc.WithTracerProvider(tp))),
+		grpc.WithChainUnaryInterceptor(requestIDInterceptor))
+	if err != nil {
+		logger.Logger.Error("error creating posts service grpc client", "error", err.Error())
+		return nil, err
+	}
+
+	c := &PostsClient{
+		client: pb.NewPostsServiceClient(conn),
+		ttl:    cfg.PostMetaCacheTTL,
+		cache:  make(map[int32]postMetaEntry),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			if c.ttl > 0 {
+				go c.sweep(ctx)
+			}
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			return conn.Close()
+		},
+	})
+
+	return c, nil
+}
+
+func (c *PostsClient) GetPostMeta(ctx context.Context, postID int32) (*pb.PostMetaResponse, error) {
+	c.mu.Lock()
+	entry, ok := c.cache[postID]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.meta, nil
+	}
+
+	meta, err := c.client.GetPostMeta(ctx, &pb.PostID{PostId: postID})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, errors.PostNotFoundError{}
+		}
+		logger.FromContext(ctx).Error("error grpc request GetPostMeta", "error", err.Error(), "post_id", postID)
+		return nil, err
+	}
+
+	c.put(meta)
+
+	return meta, nil
+}
+
+// GetPostsMeta looks up the posts missing from the cache with one request, unknown posts
+// are left out of the result.
+func (c *PostsClient) GetPostsMeta(ctx context.Context, postIDs []int32) (map[int32]*pb.PostMetaResponse, error) {
+	metas := make(map[int32]*pb.PostMetaResponse, len(postIDs))
+	var missing []int32
+	now := time.Now()
+	c.mu.Lock()
+	for _, id := range postIDs {
+		if entry, ok := c.cache[id]; ok && now.Before(entry.expiresAt) {
+			metas[id] = entry.meta
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	c.mu.Unlock()
+	if len(missing) == 0 {
+		return metas, nil
+	}
+
+	res, err := c.client.GetPostsMeta(ctx, &pb.BatchPostsMetaRequest{PostIds: missing})
+	if err != nil {
+		logger.FromContext(ctx).Error("error grpc request GetPostsMeta", "error", err.Error(), "posts", len(missing))
+		return nil, err
+	}
+
+	c.put(res.Posts...)
+	for _, meta := range res.Posts {
+		metas[meta.PostId] = meta
+	}
+
+	return metas, nil
+}
+
+func (c *PostsClient) put(metas ...*pb.PostMetaResponse) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expiresAt := time.Now().Add(c.ttl)
+	for _, meta := range metas {
+		c.cache[meta.PostId] = postMetaEntry{meta: meta, expiresAt: expiresAt}
+	}
+}
+
+// sweep drops the expired answers, so that posts asked about once do not stay in memory.
+func (c *PostsClient) sweep(ctx context.Context) {
+	ticker := time.NewTicker(c.ttl)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			c.mu.Lock()
+			for id, e := range c.cache {
+				if now.After(e.expiresAt) {
+					delete(c.cache, id)
+				}
+			}
+			c.mu.Unlock()
+		}
+	}
+}
@@ -3,31 +3,50 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/uptrace/opentelemetry-go-extra/otelsql"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 
 	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/config"
 	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/logger"
 )
 
-func CreateDbTables(conn *sql.DB) error {
-	var queries []string
-	tableNames := []string{"comments", "likes", "views"}
-	for _, name := range tableNames {
-		queries = append(queries, fmt.Sprintf(
-			`CREATE TABLE IF NOT EXISTS %s (
+const tableSchema = `CREATE TABLE IF NOT EXISTS %s (
+                		event_id UUID,
                 		time DateTime('UTC'),
   						user_id Int32,
-  						post_id Int32
+  						post_id Int32,
+  						author_id Int32,
+  						sign Int8 DEFAULT 1
         			)  
-            		ENGINE = MergeTree() 
+            		ENGINE = ReplacingMergeTree() 
  					PARTITION BY toYYYYMM(time)
- 					ORDER BY (time)`, name))
-	}
+ 					ORDER BY (post_id, event_id)`
 
-	for _, query := range queries {
-		_, err := conn.Exec(query)
+var tableNames = []string{"comments", "likes", "views"}
+
+// CreateDbTables creates the statistic tables. Rows are deduplicated by event_id, so events
+// redelivered by Kafka or retried by producers are stored once after the parts are merged.
+// Queries count uniqExact(event_id) to stay correct before that. Events undoing earlier ones,
+// the unlikes, are stored with sign -1 and subtracted.
+func CreateDbTables(conn *sql.DB) error {
+	for _, name := range tableNames {
+		if err := migrateTable(conn, name); err != nil {
+			return err
+		}
+
+		_, err := conn.Exec(fmt.Sprintf(tableSchema, name))
+		if err != nil {
+			logger.Logger.Error("error execing query", "error", err)
+			return err
+		}
+
+		_, err = conn.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS sign Int8 DEFAULT 1`, name))
 		if err != nil {
 			logger.Logger.Error("error execing query", "error", err)
 			return err
@@ -37,26 +56,36 @@ func CreateDbTables(conn *sql.DB) error {
 	return nil
 }
 
-func CreateKafkaTables(conn *sql.DB) error {
-	var queries []string
-	tableNames := []string{"commentskafka", "likeskafka", "viewskafka"}
-	topics := []string{"comments.topic", "likes.topic", "views.topic"}
-	for i := range tableNames {
-		queries = append(queries, fmt.Sprintf(
-			`CREATE TABLE IF NOT EXISTS %s (
-  						time DateTime('UTC'),
-  						user_id Int32,
-  						post_id Int32
- 					)
- 					ENGINE = Kafka()
- 					SETTINGS kafka_broker_list = 'kafka:9092',
-    				kafka_topic_list = '%s',
-    				kafka_group_name = 'clickhouse_%s_consumer',
-					kafka_format = 'JSONEachRow',
-					kafka_num_consumers = 1,
-					kafka_skip_broken_messages = 1,
-					date_time_input_format = 'best_effort'`, tableNames[i], topics[i], topics[i]))
+// legacyEventID is the id of a row stored before events had ids, the MD5 of
+// "<table>/<user_id>/<post_id>/<unix time>". It must stay equal to the id kafka.legacyEventID
+// derives for the messages without one, so that replayed old messages are deduplicated.
+const legacyEventID = `toUUID(UUIDNumToString(MD5(concat('%s/', toString(user_id), '/', toString(post_id), '/', 
+					toString(toUnixTimestamp(time))))))`
+
+// migrateTable moves a table created before events had ids to ReplacingMergeTree. Old rows get
+// the id derived from their contents, the same one their messages get when they are replayed.
+func migrateTable(conn *sql.DB, name string) error {
+	var engine string
+	err := conn.QueryRow("SELECT engine FROM system.tables WHERE database = currentDatabase() AND name = ?", name).Scan(&engine)
+	if errors.Is(err, sql.ErrNoRows) || engine == "ReplacingMergeTree" {
+		return nil
+	}
+	if err != nil {
+		logger.Logger.Error("error getting table engine", "error", err, "table", name)
+		return err
+	}
 
+	logger.Logger.Info("migrating table to ReplacingMergeTree", "table", name, "engine", engine)
+
+	tmp := name + "_dedup"
+	queries := []string{
+		fmt.Sprintf(`DROP TABLE IF EXISTS %s`, tmp),
+		fmt.Sprintf(tableSchema, tmp),
+		fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS author_id Int32`, name),
+		fmt.Sprintf(`INSERT INTO %s (event_id, time, user_id, post_id, author_id) 
+					SELECT %s, time, user_id, post_id, author_id FROM %s`, tmp, fmt.Sprintf(legacyEventID, name), name),
+		fmt.Sprintf(`EXCHANGE TABLES %s AND %s`, name, tmp),
+		fmt.Sprintf(`DROP TABLE %s`, tmp),
 	}
 
 	for _, query := range queries {
@@ -70,22 +99,15 @@ func CreateKafkaTables(conn *sql.DB) error {
 	return nil
 }
 
-func CreateMwTables(conn *sql.DB) error {
+// DropKafkaTables removes the Kafka engine tables and their materialized views left from the
+// time ClickHouse consumed the topics itself. Events are now consumed by the service.
+func DropKafkaTables(conn *sql.DB) error {
 	var queries []string
 	mwTableNames := []string{"commentsmw", "likesmw", "viewsmw"}
-	dbTableNames := []string{"comments", "likes", "views"}
 	kafkaTableNames := []string{"commentskafka", "likeskafka", "viewskafka"}
-
 	for i := range mwTableNames {
-		queries = append(queries, fmt.Sprintf(
-			`CREATE MATERIALIZED VIEW IF NOT EXISTS %s
-   						TO %s
-   						AS 
-   						SELECT 
-    						time,
-    						user_id,
-    						post_id
-						FROM %s`, mwTableNames[i], dbTableNames[i], kafkaTableNames[i]))
+		queries = append(queries, fmt.Sprintf(`DROP VIEW IF EXISTS %s`, mwTableNames[i]))
+		queries = append(queries, fmt.Sprintf(`DROP TABLE IF EXISTS %s`, kafkaTableNames[i]))
 	}
 
 	for _, query := range queries {
@@ -99,35 +121,35 @@ func CreateMwTables(conn *sql.DB) error {
 	return nil
 }
 
-func InitDb(lc fx.Lifecycle, cfg *config.Config) (*sql.DB, error) {
+func InitDb(lc fx.Lifecycle, cfg *config.Config, tp trace.TracerProvider, reg prometheus.Registerer) (*sql.DB, error) {
 	logger.Logger.Info("Connecting to ClickHouse")
 
-	conn := sql.OpenDB(clickhouse.Connector(&clickhouse.Options{
+	conn := otelsql.OpenDB(clickhouse.Connector(&clickhouse.Options{
 		Addr: []string{fmt.Sprintf("%s%s", cfg.ClickHouseHost, cfg.ClickHousePort)},
 		Auth: clickhouse.Auth{
 			Database: cfg.ClickHouseDb,
 			Username: cfg.ClickHouseUser,
 			Password: cfg.ClickHousePassword,
 		},
-	}))
+	}), otelsql.WithTracerProvider(tp), otelsql.WithDBSystem("clickhouse"))
 
 	if err := conn.Ping(); err != nil {
 		logger.Logger.Error("ping clickhouse error", "error", err.Error())
 		return nil, err
 	}
 
-	if err := CreateDbTables(conn); err != nil {
-		logger.Logger.Error("create db tables error", "error", err.Error())
-		return nil, err
-	}
+	reg.MustRegister(collectors.NewDBStatsCollector(conn, cfg.ClickHouseDb))
 
-	if err := CreateKafkaTables(conn); err != nil {
-		logger.Logger.Error("create kafka tables error", "error", err.Error())
+	// The Kafka engine tables are dropped first, so that they stop inserting and committing
+	// offsets before the tables are migrated. SeedOffsets runs after InitDb, in OnStart of the
+	// consumer, and picks up from the last offsets they committed.
+	if err := DropKafkaTables(conn); err != nil {
+		logger.Logger.Error("drop kafka tables error", "error", err.Error())
 		return nil, err
 	}
 
-	if err := CreateMwTables(conn); err != nil {
-		logger.Logger.Error("create mw tables error", "error", err.Error())
+	if err := CreateDbTables(conn); err != nil {
+		logger.Logger.Error("create db tables error", "error", err.Error())
 		return nil, err
 	}
 
@@ -0,0 +1,308 @@
+package kafka
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	pb "github.com/grigorovskiiy/soa-hse/protos"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/config"
+	statErrors "github.com/grigorovskiiy/soa-hse/statistic_service/internal/errors"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"google.golang.org/protobuf/proto"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	contentTypeHeader   = "content-type"
+	contentTypeProtobuf = "application/x-protobuf"
+	eventVersion        = 2
+)
+
+type EventsHandler interface {
+	SaveEvents(ctx context.Context, par string, events []*models.Event) error
+}
+
+type Consumer struct {
+	readers map[string]*kafka.Reader
+	dlq     *kafka.Writer
+	handler EventsHandler
+	cfg     *config.Config
+	metrics *Metrics
+	tracer  trace.Tracer
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func NewConsumer(cfg *config.Config, handler EventsHandler, tp trace.TracerProvider, reg prometheus.Registerer) *Consumer {
+	topics := map[string]string{
+		"views":    cfg.ViewsTopic,
+		"likes":    cfg.LikesTopic,
+		"comments": cfg.CommentsTopic,
+	}
+
+	readers := make(map[string]*kafka.Reader, len(topics))
+	for par, topic := range topics {
+		readers[par] = kafka.NewReader(kafka.ReaderConfig{
+			Brokers:  cfg.Brokers,
+			GroupID:  cfg.ConsumerGroup,
+			Topic:    topic,
+			MinBytes: 1,
+			MaxBytes: 10e6,
+		})
+	}
+
+	c := &Consumer{
+		readers: readers,
+		dlq: &kafka.Writer{
+			Addr:                   kafka.TCP(cfg.Brokers...),
+			Topic:                  cfg.DeadLetterTopic,
+			Balancer:               &kafka.LeastBytes{},
+			AllowAutoTopicCreation: true,
+		},
+		handler: handler,
+		cfg:     cfg,
+		metrics: NewMetrics(),
+		tracer:  tp.Tracer("kafka"),
+	}
+	reg.MustRegister(c.metrics)
+
+	return c
+}
+
+func RunConsumer(lc fx.Lifecycle, c *Consumer) error {
+	lc.Append(fx.Hook{
+		OnStart: func(startCtx context.Context) error {
+			topics := make([]string, 0, len(c.readers))
+			for _, reader := range c.readers {
+				topics = append(topics, reader.Config().Topic)
+			}
+			if err := SeedOffsets(startCtx, c.cfg, topics); err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			c.cancel = cancel
+			for par, reader := range c.readers {
+				c.wg.Add(1)
+				go func() {
+					defer c.wg.Done()
+					c.consume(ctx, par, reader)
+				}()
+			}
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			c.cancel()
+			c.wg.Wait()
+
+			var errs []error
+			for _, reader := range c.readers {
+				errs = append(errs, reader.Close())
+			}
+			errs = append(errs, c.dlq.Close())
+
+			return errors.Join(errs...)
+		},
+	})
+
+	return nil
+}
+
+func (c *Consumer) consume(ctx context.Context, par string, reader *kafka.Reader) {
+	logger := logger.Logger.With("topic", reader.Config().Topic, "par", par)
+	logger.Info("kafka consumer started")
+
+	for ctx.Err() == nil {
+		msgs := c.fetchBatch(ctx, reader)
+		if len(msgs) == 0 {
+			continue
+		}
+
+		batchCtx, span := startBatchSpan(ctx, c.tracer, reader.Config().Topic, msgs)
+		events := make([]*models.Event, 0, len(msgs))
+		for _, msg := range msgs {
+			event, err := decodeEvent(par, msg)
+			if err != nil {
+				c.metrics.invalid(par)
+				logger.Error("invalid kafka event", "error", err.Error(), "partition", msg.Partition, "offset", msg.Offset,
+					"request_id", requestID(&msg))
+				c.retry(ctx, logger, "dead letter", func() error { return c.deadLetter(ctx, msg, err) })
+				continue
+			}
+			events = append(events, event)
+		}
+
+		if len(events) > 0 {
+			c.retry(ctx, logger, "save events", func() error {
+				err := c.handler.SaveEvents(batchCtx, par, events)
+				if err != nil {
+					c.metrics.failed(par)
+				}
+				return err
+			})
+		}
+		span.End()
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		c.retry(ctx, logger, "commit messages", func() error { return reader.CommitMessages(ctx, msgs...) })
+
+		c.metrics.consumed(par, len(events), reader.Stats().Lag)
+		m := c.metrics.Snapshot()[par]
+		logger.Info("kafka events batch saved", "events", len(events), "messages", len(msgs),
+			"consumed_total", m.Consumed, "invalid_total", m.Invalid, "save_failures_total", m.SaveFailures, "lag", m.Lag)
+	}
+
+	logger.Info("kafka consumer stopped")
+}
+
+// fetchBatch reads messages until the batch is full or the flush interval is over.
+func (c *Consumer) fetchBatch(ctx context.Context, reader *kafka.Reader) []kafka.Message {
+	fetchCtx, cancel := context.WithTimeout(ctx, c.cfg.FlushInterval)
+	defer cancel()
+
+	var msgs []kafka.Message
+	for len(msgs) < c.cfg.BatchSize {
+		msg, err := reader.FetchMessage(fetchCtx)
+		if err != nil {
+			if !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+				logger.Logger.Error("kafka fetch message error", "topic", reader.Config().Topic, "error", err.Error())
+			}
+			break
+		}
+		msgs = append(msgs, msg)
+	}
+
+	return msgs
+}
+
+// retry repeats f until it succeeds or the consumer is stopped, so that offsets
+// are never committed before the batch is stored.
+func (c *Consumer) retry(ctx context.Context, logger *slog.Logger, op string, f func() error) {
+	for {
+		err := f()
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+
+		logger.Error("kafka consumer "+op+" error", "error", err.Error())
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.cfg.RetryBackoff):
+		}
+	}
+}
+
+func (c *Consumer) deadLetter(ctx context.Context, msg kafka.Message, reason error) error {
+	return c.dlq.WriteMessages(ctx, NewDeadLetterMessage(msg, reason))
+}
+
+// decodeEvent supports both the protobuf envelope (version 2) and the plain JSON
+// payload (version 1) that older producers still may have left in the topics.
+func decodeEvent(par string, msg kafka.Message) (*models.Event, error) {
+	var (
+		event *models.Event
+		err   error
+	)
+	if header(msg, contentTypeHeader) == contentTypeProtobuf {
+		event, err = decodeEnvelope(msg.Value)
+	} else {
+		event, err = decodeJSONEvent(msg.Value)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if event.EventId == uuid.Nil {
+		event.EventId = legacyEventID(par, event)
+	}
+
+	switch {
+	case event.PostId <= 0:
+		return nil, statErrors.InvalidEventError{Reason: "post_id is empty"}
+	case event.UserId <= 0:
+		return nil, statErrors.InvalidEventError{Reason: "user_id is empty"}
+	case event.Time.IsZero():
+		return nil, statErrors.InvalidEventError{Reason: "time is empty"}
+	}
+
+	return event, nil
+}
+
+func decodeEnvelope(value []byte) (*models.Event, error) {
+	var env pb.EventEnvelope
+	if err := proto.Unmarshal(value, &env); err != nil {
+		return nil, statErrors.InvalidEventError{Reason: err.Error()}
+	}
+
+	if env.Version != eventVersion {
+		return nil, statErrors.InvalidEventError{Reason: "unsupported event version " + strconv.Itoa(int(env.Version))}
+	}
+
+	interaction := env.GetInteraction()
+	if interaction == nil {
+		return nil, statErrors.InvalidEventError{Reason: "unexpected payload of event " + env.Type.String()}
+	}
+
+	eventID, err := uuid.Parse(env.EventId)
+	if err != nil {
+		return nil, statErrors.InvalidEventError{Reason: "event_id is invalid: " + err.Error()}
+	}
+
+	event := models.Event{
+		EventId:  eventID,
+		UserId:   int(interaction.UserId),
+		PostId:   int(interaction.PostId),
+		AuthorId: int(interaction.AuthorId),
+		Sign:     1,
+	}
+	if env.Type == pb.EventType_POST_UNLIKED {
+		event.Sign = -1
+	}
+	if env.OccurredAt != nil {
+		event.Time = env.OccurredAt.AsTime()
+	}
+
+	return &event, nil
+}
+
+func decodeJSONEvent(value []byte) (*models.Event, error) {
+	event := models.Event{Sign: 1}
+	if err := json.Unmarshal(value, &event); err != nil {
+		return nil, statErrors.InvalidEventError{Reason: err.Error()}
+	}
+
+	return &event, nil
+}
+
+// legacyEventID derives the id of an event that was produced without one from its contents,
+// so that redeliveries of the message and the row migrated from before events had ids get the
+// same id. It is the MD5 of "<par>/<user_id>/<post_id>/<unix time>" and must stay equal to
+// db.legacyEventID.
+func legacyEventID(par string, event *models.Event) uuid.UUID {
+	return md5.Sum([]byte(par + "/" + strconv.Itoa(event.UserId) + "/" + strconv.Itoa(event.PostId) + "/" +
+		strconv.FormatInt(event.Time.Unix(), 10)))
+}
+
+func header(msg kafka.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+
+	return ""
+}
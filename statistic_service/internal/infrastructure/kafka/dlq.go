@@ -0,0 +1,232 @@
+package kafka
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	pb "github.com/grigorovskiiy/soa-hse/protos"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/config"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/models"
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	errorHeader             = "dlq.error"
+	failedAtHeader          = "dlq.failed_at"
+	originalTopicHeader     = "dlq.original_topic"
+	originalPartitionHeader = "dlq.original_partition"
+	originalOffsetHeader    = "dlq.original_offset"
+)
+
+// NewDeadLetterMessage wraps a message that could not be processed together with the reason
+// and its original position, so that it can be inspected and replayed later. The headers are
+// copied, msg is left as it is.
+func NewDeadLetterMessage(msg kafka.Message, reason error) kafka.Message {
+	return kafka.Message{
+		Key:   msg.Key,
+		Value: msg.Value,
+		Headers: append(slices.Clone(msg.Headers),
+			kafka.Header{Key: errorHeader, Value: []byte(reason.Error())},
+			kafka.Header{Key: failedAtHeader, Value: []byte(time.Now().UTC().Format(time.RFC3339Nano))},
+			kafka.Header{Key: originalTopicHeader, Value: []byte(msg.Topic)},
+			kafka.Header{Key: originalPartitionHeader, Value: []byte(strconv.Itoa(msg.Partition))},
+			kafka.Header{Key: originalOffsetHeader, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		),
+	}
+}
+
+func FromDeadLetterMessage(msg kafka.Message) *models.DeadLetter {
+	d := models.DeadLetter{
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Key:       string(msg.Key),
+	}
+	d.Encoding, d.Value = encodeValue(msg)
+
+	for _, h := range msg.Headers {
+		switch h.Key {
+		case errorHeader:
+			d.Error = string(h.Value)
+		case failedAtHeader:
+			d.FailedAt, _ = time.Parse(time.RFC3339Nano, string(h.Value))
+		case originalTopicHeader:
+			d.OriginalTopic = string(h.Value)
+		case originalPartitionHeader:
+			d.OriginalPartition, _ = strconv.Atoi(string(h.Value))
+		case originalOffsetHeader:
+			d.OriginalOffset, _ = strconv.ParseInt(string(h.Value), 10, 64)
+		default:
+			d.Headers = append(d.Headers, models.DeadLetterHeader{Key: h.Key, Value: string(h.Value)})
+		}
+	}
+
+	return &d
+}
+
+const (
+	protojsonEncoding = "protojson"
+	base64Encoding    = "base64"
+)
+
+// encodeValue makes the value printable: protobuf events become editable JSON,
+// other binary values are base64 encoded.
+func encodeValue(msg kafka.Message) (string, string) {
+	if header(msg, contentTypeHeader) == contentTypeProtobuf {
+		var env pb.EventEnvelope
+		if err := proto.Unmarshal(msg.Value, &env); err == nil {
+			if value, err := protojson.Marshal(&env); err == nil {
+				return protojsonEncoding, string(value)
+			}
+		}
+	}
+
+	if !utf8.Valid(msg.Value) {
+		return base64Encoding, base64.StdEncoding.EncodeToString(msg.Value)
+	}
+
+	return "", string(msg.Value)
+}
+
+func decodeValue(d *models.DeadLetter) ([]byte, error) {
+	switch d.Encoding {
+	case "":
+		return []byte(d.Value), nil
+	case base64Encoding:
+		return base64.StdEncoding.DecodeString(d.Value)
+	case protojsonEncoding:
+		var env pb.EventEnvelope
+		if err := protojson.Unmarshal([]byte(d.Value), &env); err != nil {
+			return nil, err
+		}
+		return proto.Marshal(&env)
+	default:
+		return nil, errors.New("unknown dead letter encoding " + d.Encoding)
+	}
+}
+
+func toReplayMessage(d *models.DeadLetter) (kafka.Message, error) {
+	value, err := decodeValue(d)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+
+	msg := kafka.Message{
+		Topic: d.OriginalTopic,
+		Value: value,
+	}
+	if d.Key != "" {
+		msg.Key = []byte(d.Key)
+	}
+	for _, h := range d.Headers {
+		msg.Headers = append(msg.Headers, kafka.Header{Key: h.Key, Value: []byte(h.Value)})
+	}
+
+	return msg, nil
+}
+
+// ReadDeadLetters reads up to limit messages of every dead letter topic partition without
+// committing any offsets. A non-positive limit reads all of them.
+func ReadDeadLetters(ctx context.Context, cfg *config.Config, limit int) ([]*models.DeadLetter, error) {
+	conn, err := kafka.DialContext(ctx, "tcp", cfg.Brokers[0])
+	if err != nil {
+		logger.Logger.Error("kafka dial error", "error", err.Error())
+		return nil, err
+	}
+	defer conn.Close()
+
+	partitions, err := conn.ReadPartitions(cfg.DeadLetterTopic)
+	if err != nil {
+		logger.Logger.Error("kafka read partitions error", "error", err.Error())
+		return nil, err
+	}
+
+	var letters []*models.DeadLetter
+	for _, p := range partitions {
+		read, err := readPartition(ctx, cfg, p.ID, limit-len(letters))
+		if err != nil {
+			return nil, err
+		}
+		letters = append(letters, read...)
+		if limit > 0 && len(letters) >= limit {
+			break
+		}
+	}
+
+	return letters, nil
+}
+
+func readPartition(ctx context.Context, cfg *config.Config, partition int, limit int) ([]*models.DeadLetter, error) {
+	leader, err := kafka.DialLeader(ctx, "tcp", cfg.Brokers[0], cfg.DeadLetterTopic, partition)
+	if err != nil {
+		logger.Logger.Error("kafka dial leader error", "error", err.Error(), "partition", partition)
+		return nil, err
+	}
+	first, last, err := leader.ReadOffsets()
+	leader.Close()
+	if err != nil {
+		logger.Logger.Error("kafka read offsets error", "error", err.Error(), "partition", partition)
+		return nil, err
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   cfg.Brokers,
+		Topic:     cfg.DeadLetterTopic,
+		Partition: partition,
+	})
+	defer reader.Close()
+
+	if err = reader.SetOffset(first); err != nil {
+		logger.Logger.Error("kafka set offset error", "error", err.Error(), "partition", partition)
+		return nil, err
+	}
+
+	var letters []*models.DeadLetter
+	for offset := first; offset < last && (limit <= 0 || len(letters) < limit); offset++ {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			logger.Logger.Error("kafka read message error", "error", err.Error(), "partition", partition)
+			return nil, err
+		}
+		letters = append(letters, FromDeadLetterMessage(msg))
+		offset = msg.Offset
+	}
+
+	return letters, nil
+}
+
+// Replay sends dead letters back to the topics they were consumed from.
+func Replay(ctx context.Context, cfg *config.Config, letters []*models.DeadLetter) error {
+	writer := &kafka.Writer{
+		Addr:     kafka.TCP(cfg.Brokers...),
+		Balancer: &kafka.Hash{},
+	}
+	defer writer.Close()
+
+	msgs := make([]kafka.Message, 0, len(letters))
+	for _, d := range letters {
+		if d.OriginalTopic == "" {
+			logger.Logger.Error("dead letter has no original topic", "partition", d.Partition, "offset", d.Offset)
+			return errors.New("dead letter has no original topic")
+		}
+		msg, err := toReplayMessage(d)
+		if err != nil {
+			logger.Logger.Error("dead letter value decode error", "error", err.Error(), "partition", d.Partition, "offset", d.Offset)
+			return err
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if err := writer.WriteMessages(ctx, msgs...); err != nil {
+		logger.Logger.Error("kafka replay dead letters error", "error", err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package kafka
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sync"
+)
+
+type TopicMetrics struct {
+	Consumed     int64
+	Invalid      int64
+	SaveFailures int64
+	Lag          int64
+}
+
+var (
+	consumerMessagesDesc = prometheus.NewDesc("kafka_consumer_messages_total",
+		"Number of consumed messages by statistic table and result: consumed, invalid or save_failed.",
+		[]string{"table", "result"}, nil)
+	consumerLagDesc = prometheus.NewDesc("kafka_consumer_lag",
+		"Lag of the reader of the statistic table after the last batch.",
+		[]string{"table"}, nil)
+)
+
+// Metrics keeps per-topic consumer counters. It is a prometheus.Collector, the counters
+// are exported as kafka_consumer_messages_total and kafka_consumer_lag.
+type Metrics struct {
+	mu     sync.Mutex
+	topics map[string]*TopicMetrics
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{topics: make(map[string]*TopicMetrics)}
+}
+
+func (m *Metrics) get(par string) *TopicMetrics {
+	t, ok := m.topics[par]
+	if !ok {
+		t = &TopicMetrics{}
+		m.topics[par] = t
+	}
+
+	return t
+}
+
+func (m *Metrics) consumed(par string, n int, lag int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := m.get(par)
+	t.Consumed += int64(n)
+	t.Lag = lag
+}
+
+func (m *Metrics) invalid(par string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.get(par).Invalid++
+}
+
+func (m *Metrics) failed(par string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.get(par).SaveFailures++
+}
+
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- consumerMessagesDesc
+	ch <- consumerLagDesc
+}
+
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	for par, t := range m.Snapshot() {
+		for result, v := range map[string]int64{"consumed": t.Consumed, "invalid": t.Invalid, "save_failed": t.SaveFailures} {
+			ch <- prometheus.MustNewConstMetric(consumerMessagesDesc, prometheus.CounterValue, float64(v), par, result)
+		}
+		ch <- prometheus.MustNewConstMetric(consumerLagDesc, prometheus.GaugeValue, float64(t.Lag), par)
+	}
+}
+
+// Snapshot returns a copy of the current counters keyed by statistic table.
+func (m *Metrics) Snapshot() map[string]TopicMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	res := make(map[string]TopicMetrics, len(m.topics))
+	for par, t := range m.topics {
+		res[par] = *t
+	}
+
+	return res
+}
@@ -0,0 +1,107 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/config"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/logger"
+	"github.com/segmentio/kafka-go"
+)
+
+// legacyGroup is the consumer group of the ClickHouse Kafka engine table that consumed topic
+// before the service did.
+func legacyGroup(topic string) string {
+	return "clickhouse_" + topic + "_consumer"
+}
+
+// SeedOffsets starts ConsumerGroup at the offsets committed by the legacy group on the topics the
+// group has not consumed yet, so that the events ClickHouse has already stored are not read again.
+// Topics without legacy offsets are read from the beginning.
+func SeedOffsets(ctx context.Context, cfg *config.Config, topics []string) error {
+	client := &kafka.Client{Addr: kafka.TCP(cfg.Brokers...)}
+
+	meta, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: topics})
+	if err != nil {
+		logger.Logger.Error("kafka metadata error", "error", err.Error())
+		return err
+	}
+
+	for _, topic := range meta.Topics {
+		// A topic that does not exist yet has nothing consumed by the legacy group.
+		if topic.Error != nil {
+			continue
+		}
+
+		partitions := make([]int, len(topic.Partitions))
+		for i, p := range topic.Partitions {
+			partitions[i] = p.ID
+		}
+
+		if err = seedTopic(ctx, client, cfg.ConsumerGroup, topic.Name, partitions); err != nil {
+			logger.Logger.Error("seed consumer group offsets error", "error", err.Error(), "topic", topic.Name)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func seedTopic(ctx context.Context, client *kafka.Client, group, topic string, partitions []int) error {
+	current, err := committedOffsets(ctx, client, group, topic, partitions)
+	if err != nil || len(current) > 0 {
+		return err
+	}
+
+	legacy, err := committedOffsets(ctx, client, legacyGroup(topic), topic, partitions)
+	if err != nil || len(legacy) == 0 {
+		return err
+	}
+
+	// Offsets of an empty group are committed outside of a generation.
+	res, err := client.OffsetCommit(ctx, &kafka.OffsetCommitRequest{
+		GroupID:      group,
+		GenerationID: -1,
+		Topics:       map[string][]kafka.OffsetCommit{topic: legacy},
+	})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, p := range res.Topics[topic] {
+		errs = append(errs, p.Error)
+	}
+	if err = errors.Join(errs...); err != nil {
+		return err
+	}
+
+	logger.Logger.Info("consumer group offsets seeded from the legacy group", "topic", topic,
+		"group", group, "legacy_group", legacyGroup(topic))
+	return nil
+}
+
+// committedOffsets returns the offsets committed by group on the partitions of topic.
+func committedOffsets(ctx context.Context, client *kafka.Client, group, topic string, partitions []int) ([]kafka.OffsetCommit, error) {
+	res, err := client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{
+		GroupID: group,
+		Topics:  map[string][]int{topic: partitions},
+	})
+	if err == nil {
+		err = res.Error
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var offsets []kafka.OffsetCommit
+	for _, p := range res.Topics[topic] {
+		if p.Error != nil {
+			return nil, p.Error
+		}
+		if p.CommittedOffset >= 0 {
+			offsets = append(offsets, kafka.OffsetCommit{Partition: p.Partition, Offset: p.CommittedOffset})
+		}
+	}
+
+	return offsets, nil
+}
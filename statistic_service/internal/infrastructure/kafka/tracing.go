@@ -0,0 +1,70 @@
+package kafka
+
+import (
+	"context"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/logger"
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// requestID is the ID of the request that caused the event, set by the producer.
+func requestID(msg *kafka.Message) string {
+	return headerCarrier{msg: msg}.Get(logger.RequestIDKey)
+}
+
+// headerCarrier lets the propagator write and read the trace context in kafka message headers.
+type headerCarrier struct {
+	msg *kafka.Message
+}
+
+func (c headerCarrier) Get(key string) string {
+	for _, h := range c.msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+
+	return ""
+}
+
+func (c headerCarrier) Set(key, value string) {
+	for i, h := range c.msg.Headers {
+		if h.Key == key {
+			c.msg.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+
+	c.msg.Headers = append(c.msg.Headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, len(c.msg.Headers))
+	for i, h := range c.msg.Headers {
+		keys[i] = h.Key
+	}
+
+	return keys
+}
+
+// startBatchSpan starts the span of a consumed batch. A batch has many parents, so the span
+// is linked to the publish span of every message instead.
+func startBatchSpan(ctx context.Context, tracer trace.Tracer, topic string, msgs []kafka.Message) (context.Context, trace.Span) {
+	links := make([]trace.Link, 0, len(msgs))
+	for i := range msgs {
+		msgCtx := otel.GetTextMapPropagator().Extract(ctx, headerCarrier{msg: &msgs[i]})
+		if sc := trace.SpanContextFromContext(msgCtx); sc.IsValid() {
+			links = append(links, trace.Link{SpanContext: sc})
+		}
+	}
+
+	return tracer.Start(ctx, topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithLinks(links...),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingDestinationName(topic),
+			semconv.MessagingBatchMessageCount(len(msgs))))
+}
@@ -0,0 +1,68 @@
+package kafka
+
+import (
+	"context"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/tracing"
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+)
+
+func TestBatchSpanLinksPublishSpans(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	exporter := tracetest.NewInMemoryExporter()
+	tp := tracing.NewProvider(exporter, 1)
+	t.Cleanup(func() {
+		_ = tp.Shutdown(context.Background())
+	})
+
+	// Two messages published in different traces and one without a trace context.
+	msgs := make([]kafka.Message, 3)
+	var published []trace.SpanContext
+	for i := range 2 {
+		ctx, span := tp.Tracer("producer").Start(context.Background(), "likes publish")
+		otel.GetTextMapPropagator().Inject(ctx, headerCarrier{msg: &msgs[i]})
+		span.End()
+		published = append(published, span.SpanContext())
+	}
+	headerCarrier{msg: &msgs[0]}.Set(logger.RequestIDKey, "req-1")
+
+	_, span := startBatchSpan(context.Background(), tp.Tracer("kafka"), "likes", msgs)
+	span.End()
+	if err := tp.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("flush spans: %v", err)
+	}
+
+	var process tracetest.SpanStub
+	for _, s := range exporter.GetSpans() {
+		if s.Name == "likes process" {
+			process = s
+		}
+	}
+	if process.Name == "" {
+		t.Fatalf("no process span in %d spans", len(exporter.GetSpans()))
+	}
+	if process.SpanKind != trace.SpanKindConsumer {
+		t.Errorf("span kind %v, want consumer", process.SpanKind)
+	}
+	if len(process.Links) != len(published) {
+		t.Fatalf("%d links, want %d", len(process.Links), len(published))
+	}
+	for i, link := range process.Links {
+		if link.SpanContext.TraceID() != published[i].TraceID() || link.SpanContext.SpanID() != published[i].SpanID() {
+			t.Errorf("link %d is %s/%s, want %s/%s", i, link.SpanContext.TraceID(), link.SpanContext.SpanID(),
+				published[i].TraceID(), published[i].SpanID())
+		}
+	}
+
+	if id := requestID(&msgs[0]); id != "req-1" {
+		t.Errorf("request id %q, want %q", id, "req-1")
+	}
+	if id := requestID(&msgs[2]); id != "" {
+		t.Errorf("request id %q of a message without one, want none", id)
+	}
+}
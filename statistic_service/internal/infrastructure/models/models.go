@@ -1,8 +1,63 @@
 package models
 
-import "time"
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Event is a view, like or comment. Sign is -1 for an event undoing an earlier one, an unlike,
+// and 1 otherwise.
+type Event struct {
+	EventId  uuid.UUID `json:"event_id"`
+	UserId   int       `json:"user_id"`
+	PostId   int       `json:"post_id"`
+	AuthorId int       `json:"author_id"`
+	Time     time.Time `json:"time"`
+	Sign     int8      `json:"-"`
+}
+
+type DeadLetter struct {
+	Partition         int                `json:"partition"`
+	Offset            int64              `json:"offset"`
+	OriginalTopic     string             `json:"original_topic"`
+	OriginalPartition int                `json:"original_partition"`
+	OriginalOffset    int64              `json:"original_offset"`
+	Error             string             `json:"error"`
+	FailedAt          time.Time          `json:"failed_at"`
+	Headers           []DeadLetterHeader `json:"headers,omitempty"`
+	Key               string             `json:"key,omitempty"`
+	// Encoding is how Value is written: empty for plain text, "protojson" for protobuf
+	// events that can be edited as JSON and "base64" for anything else that is binary.
+	Encoding string `json:"encoding,omitempty"`
+	Value    string `json:"value"`
+}
+
+type DeadLetterHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
 
 type Dynamic struct {
 	Date  time.Time
 	Count int
 }
+
+type AuthorStats struct {
+	ViewsCount         int
+	LikesCount         int
+	CommentsCount      int
+	UniqueViewersCount int
+	ViewsDynamic       []*Dynamic
+	LikesDynamic       []*Dynamic
+	CommentsDynamic    []*Dynamic
+	TopPostsByViews    []int
+	TopPostsByLikes    []int
+	TopPostsByComments []int
+}
+
+type PostStats struct {
+	PostId        int
+	ViewsCount    int
+	LikesCount    int
+	CommentsCount int
+}
@@ -10,6 +10,18 @@ import (
 	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/repository/txs"
 )
 
+// netCount counts the events less the events undoing them, the likes less the unlikes. Views and
+// comments are never undone, so for them it is uniqExact(event_id).
+const netCount = "(uniqExactIf(event_id, sign > 0) - uniqExactIf(event_id, sign < 0))"
+
+// currentLikers selects the users whose likes of the post were not undone, grouped by cols.
+const currentLikers = `
+			SELECT %s
+			FROM likes
+			WHERE post_id = ?
+			GROUP BY %s
+			HAVING uniqExactIf(event_id, sign > 0) > uniqExactIf(event_id, sign < 0)`
+
 type Repository struct {
 	db *sql.DB
 }
@@ -23,9 +35,9 @@ func NewRepository(db *sql.DB) *Repository {
 func (r *Repository) GetViewsCount(ctx context.Context, postID int) (int, error) {
 	querier := txs.GetQuerier(ctx, r.db)
 	var count int
-	err := querier.QueryRow("SELECT COUNT(*) FROM views WHERE post_id = ?", postID).Scan(&count)
+	err := querier.QueryRowContext(ctx, "SELECT uniqExact(event_id) FROM views WHERE post_id = ?", postID).Scan(&count)
 	if err != nil {
-		logger.Logger.Error("query get views count db error", "error", err.Error())
+		logger.FromContext(ctx).Error("query get views count db error", "error", err.Error())
 		return 0, err
 	}
 
@@ -35,9 +47,9 @@ func (r *Repository) GetViewsCount(ctx context.Context, postID int) (int, error)
 func (r *Repository) GetCommentsCount(ctx context.Context, postID int) (int, error) {
 	querier := txs.GetQuerier(ctx, r.db)
 	var count int
-	err := querier.QueryRow("SELECT COUNT(*) FROM comments WHERE post_id = ?", postID).Scan(&count)
+	err := querier.QueryRowContext(ctx, "SELECT uniqExact(event_id) FROM comments WHERE post_id = ?", postID).Scan(&count)
 	if err != nil {
-		logger.Logger.Error("query get comments count db error", "error", err.Error())
+		logger.FromContext(ctx).Error("query get comments count db error", "error", err.Error())
 		return 0, err
 	}
 
@@ -47,31 +59,71 @@ func (r *Repository) GetCommentsCount(ctx context.Context, postID int) (int, err
 func (r *Repository) GetLikesCount(ctx context.Context, postID int) (int, error) {
 	querier := txs.GetQuerier(ctx, r.db)
 	var count int
-	err := querier.QueryRow("SELECT COUNT(*) FROM likes WHERE post_id = ?", postID).Scan(&count)
+	err := querier.QueryRowContext(ctx, "SELECT "+netCount+" FROM likes WHERE post_id = ?", postID).Scan(&count)
 	if err != nil {
-		logger.Logger.Error("query get likes count db error", "error", err.Error())
+		logger.FromContext(ctx).Error("query get likes count db error", "error", err.Error())
 		return 0, err
 	}
 
 	return count, nil
 }
 
+// GetPostsStats counts views, likes and comments of all given posts in one query,
+// posts without any events are not returned.
+func (r *Repository) GetPostsStats(ctx context.Context, postIDs []int) ([]*models.PostStats, error) {
+	querier := txs.GetQuerier(ctx, r.db)
+	query := `
+		SELECT
+			post_id,
+			uniqExactIf(event_id, kind = 'views') as views_count,
+			uniqExactIf(event_id, kind = 'likes' AND sign > 0) - uniqExactIf(event_id, kind = 'likes' AND sign < 0) as likes_count,
+			uniqExactIf(event_id, kind = 'comments') as comments_count
+		FROM (
+			SELECT post_id, event_id, sign, 'views' as kind FROM views WHERE has(?, post_id)
+			UNION ALL
+			SELECT post_id, event_id, sign, 'likes' as kind FROM likes WHERE has(?, post_id)
+			UNION ALL
+			SELECT post_id, event_id, sign, 'comments' as kind FROM comments WHERE has(?, post_id)
+		)
+		GROUP BY post_id
+	`
+
+	rows, err := querier.QueryContext(ctx, query, postIDs, postIDs, postIDs)
+	if err != nil {
+		logger.FromContext(ctx).Error("query get posts stats db error", "error", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []*models.PostStats
+	for rows.Next() {
+		var s models.PostStats
+		if err := rows.Scan(&s.PostId, &s.ViewsCount, &s.LikesCount, &s.CommentsCount); err != nil {
+			logger.FromContext(ctx).Error("scan rows get posts stats db error", "error", err.Error())
+			return nil, err
+		}
+		stats = append(stats, &s)
+	}
+
+	return stats, nil
+}
+
 func (r *Repository) GetViewsDynamic(ctx context.Context, postID int) ([]*models.Dynamic, error) {
 
 	querier := txs.GetQuerier(ctx, r.db)
 	query := `
 		SELECT 
 			toDate(time) as date,
-			COUNT(*) as count
+			uniqExact(event_id) as count
 		FROM views
 		WHERE post_id = ?
 		GROUP BY date
 		ORDER BY date
 	`
 
-	rows, err := querier.Query(query, postID)
+	rows, err := querier.QueryContext(ctx, query, postID)
 	if err != nil {
-		logger.Logger.Error("query get views dynamic db error", "error", err.Error())
+		logger.FromContext(ctx).Error("query get views dynamic db error", "error", err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -80,7 +132,7 @@ func (r *Repository) GetViewsDynamic(ctx context.Context, postID int) ([]*models
 	for rows.Next() {
 		var d models.Dynamic
 		if err := rows.Scan(&d.Date, &d.Count); err != nil {
-			logger.Logger.Error("scan rows get views dynamic db error", "error", err.Error())
+			logger.FromContext(ctx).Error("scan rows get views dynamic db error", "error", err.Error())
 			return nil, err
 		}
 		dynamics = append(dynamics, &d)
@@ -94,16 +146,16 @@ func (r *Repository) GetCommentsDynamic(ctx context.Context, postID int) ([]*mod
 	query := `
 		SELECT 
 			toDate(time) as date,
-			COUNT(*) as count
+			uniqExact(event_id) as count
 		FROM comments
 		WHERE post_id = ?
 		GROUP BY date
 		ORDER BY date
 	`
 
-	rows, err := querier.Query(query, postID)
+	rows, err := querier.QueryContext(ctx, query, postID)
 	if err != nil {
-		logger.Logger.Error("query get comments dynamic db error", "error", err.Error())
+		logger.FromContext(ctx).Error("query get comments dynamic db error", "error", err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -112,7 +164,7 @@ func (r *Repository) GetCommentsDynamic(ctx context.Context, postID int) ([]*mod
 	for rows.Next() {
 		var d models.Dynamic
 		if err := rows.Scan(&d.Date, &d.Count); err != nil {
-			logger.Logger.Error("scan rows get comments dynamic db error", "error", err.Error())
+			logger.FromContext(ctx).Error("scan rows get comments dynamic db error", "error", err.Error())
 			return nil, err
 		}
 
@@ -127,16 +179,16 @@ func (r *Repository) GetLikesDynamic(ctx context.Context, postID int) ([]*models
 	query := `
 		SELECT 
 			toDate(time) as date,
-			COUNT(*) as count
+			` + netCount + ` as count
 		FROM likes
 		WHERE post_id = ?
 		GROUP BY date
 		ORDER BY date
 	`
 
-	rows, err := querier.Query(query, postID)
+	rows, err := querier.QueryContext(ctx, query, postID)
 	if err != nil {
-		logger.Logger.Error("query get likes dynamic db error", "error", err.Error())
+		logger.FromContext(ctx).Error("query get likes dynamic db error", "error", err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -145,7 +197,7 @@ func (r *Repository) GetLikesDynamic(ctx context.Context, postID int) ([]*models
 	for rows.Next() {
 		var d models.Dynamic
 		if err := rows.Scan(&d.Date, &d.Count); err != nil {
-			logger.Logger.Error("scan rows get likes dynamic db error", "error", err.Error())
+			logger.FromContext(ctx).Error("scan rows get likes dynamic db error", "error", err.Error())
 			return nil, err
 		}
 		dynamics = append(dynamics, &d)
@@ -154,10 +206,98 @@ func (r *Repository) GetLikesDynamic(ctx context.Context, postID int) ([]*models
 	return dynamics, nil
 }
 
-func (r *Repository) GetTopTenPosts(ctx context.Context, par string) ([]int, error) {
+func (r *Repository) GetUniqueViewersCount(ctx context.Context, postID int) (int, error) {
+	querier := txs.GetQuerier(ctx, r.db)
+	var count int
+	err := querier.QueryRowContext(ctx, "SELECT uniqExact(user_id) FROM views WHERE post_id = ?", postID).Scan(&count)
+	if err != nil {
+		logger.FromContext(ctx).Error("query get unique viewers count db error", "error", err.Error())
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *Repository) GetUniqueLikersCount(ctx context.Context, postID int) (int, error) {
+	querier := txs.GetQuerier(ctx, r.db)
+	var count int
+	err := querier.QueryRowContext(ctx, "SELECT count() FROM ("+fmt.Sprintf(currentLikers, "user_id", "user_id")+")", postID).Scan(&count)
+	if err != nil {
+		logger.FromContext(ctx).Error("query get unique likers count db error", "error", err.Error())
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *Repository) GetUniqueViewersDynamic(ctx context.Context, postID int) ([]*models.Dynamic, error) {
+	querier := txs.GetQuerier(ctx, r.db)
+	query := `
+		SELECT 
+			toDate(time) as date,
+			uniqExact(user_id) as count
+		FROM views
+		WHERE post_id = ?
+		GROUP BY date
+		ORDER BY date
+	`
+
+	rows, err := querier.QueryContext(ctx, query, postID)
+	if err != nil {
+		logger.FromContext(ctx).Error("query get unique viewers dynamic db error", "error", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dynamics []*models.Dynamic
+	for rows.Next() {
+		var d models.Dynamic
+		if err := rows.Scan(&d.Date, &d.Count); err != nil {
+			logger.FromContext(ctx).Error("scan rows get unique viewers dynamic db error", "error", err.Error())
+			return nil, err
+		}
+		dynamics = append(dynamics, &d)
+	}
+
+	return dynamics, nil
+}
+
+func (r *Repository) GetUniqueLikersDynamic(ctx context.Context, postID int) ([]*models.Dynamic, error) {
+	querier := txs.GetQuerier(ctx, r.db)
+	query := `
+		SELECT 
+			date,
+			count() as count
+		FROM (` + fmt.Sprintf(currentLikers, "toDate(time) as date, user_id", "date, user_id") + `)
+		GROUP BY date
+		ORDER BY date
+	`
+
+	rows, err := querier.QueryContext(ctx, query, postID)
+	if err != nil {
+		logger.FromContext(ctx).Error("query get unique likers dynamic db error", "error", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dynamics []*models.Dynamic
+	for rows.Next() {
+		var d models.Dynamic
+		if err := rows.Scan(&d.Date, &d.Count); err != nil {
+			logger.FromContext(ctx).Error("scan rows get unique likers dynamic db error", "error", err.Error())
+			return nil, err
+		}
+		dynamics = append(dynamics, &d)
+	}
+
+	return dynamics, nil
+}
+
+// GetTopPosts returns limit posts with the most par, starting from offset.
+func (r *Repository) GetTopPosts(ctx context.Context, par string, limit, offset int) ([]int, error) {
 	querier := txs.GetQuerier(ctx, r.db)
 	if par != "likes" && par != "comments" && par != "views" {
-		logger.Logger.Error(errors.InvalidTopParameterError{}.Error(), "par", par)
+		logger.FromContext(ctx).Error(errors.InvalidTopParameterError{}.Error(), "par", par)
 		return nil, errors.InvalidTopParameterError{}
 	}
 
@@ -165,13 +305,13 @@ func (r *Repository) GetTopTenPosts(ctx context.Context, par string) ([]int, err
 		SELECT post_id
 		FROM %s
 		GROUP BY post_id
-		ORDER BY COUNT(*) DESC
-		LIMIT 10
-	`, par)
+		ORDER BY %s DESC
+		LIMIT ? OFFSET ?
+	`, par, netCount)
 
-	rows, err := querier.Query(query)
+	rows, err := querier.QueryContext(ctx, query, limit, offset)
 	if err != nil {
-		logger.Logger.Error("query get top ten posts db error", "error", err.Error())
+		logger.FromContext(ctx).Error("query get top ten posts db error", "error", err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -180,7 +320,7 @@ func (r *Repository) GetTopTenPosts(ctx context.Context, par string) ([]int, err
 	for rows.Next() {
 		var id int
 		if err := rows.Scan(&id); err != nil {
-			logger.Logger.Error("scan rows get top ten posts db error", "error", err.Error())
+			logger.FromContext(ctx).Error("scan rows get top ten posts db error", "error", err.Error())
 			return nil, err
 		}
 		postIDs = append(postIDs, id)
@@ -192,7 +332,7 @@ func (r *Repository) GetTopTenPosts(ctx context.Context, par string) ([]int, err
 func (r *Repository) GetTopTenUsers(ctx context.Context, par string) ([]int, error) {
 	querier := txs.GetQuerier(ctx, r.db)
 	if par != "likes" && par != "comments" && par != "views" {
-		logger.Logger.Error(errors.InvalidTopParameterError{}.Error(), "par", par)
+		logger.FromContext(ctx).Error(errors.InvalidTopParameterError{}.Error(), "par", par)
 		return nil, errors.InvalidTopParameterError{}
 	}
 
@@ -200,13 +340,13 @@ func (r *Repository) GetTopTenUsers(ctx context.Context, par string) ([]int, err
 		SELECT user_id
 		FROM %s
 		GROUP BY user_id
-		ORDER BY COUNT(*) DESC
+		ORDER BY %s DESC
 		LIMIT 10
-	`, par)
+	`, par, netCount)
 
-	rows, err := querier.Query(query)
+	rows, err := querier.QueryContext(ctx, query)
 	if err != nil {
-		logger.Logger.Error("query get top ten users db error", "error", err.Error())
+		logger.FromContext(ctx).Error("query get top ten users db error", "error", err.Error())
 		return nil, err
 	}
 	defer rows.Close()
@@ -215,7 +355,7 @@ func (r *Repository) GetTopTenUsers(ctx context.Context, par string) ([]int, err
 	for rows.Next() {
 		var id int
 		if err := rows.Scan(&id); err != nil {
-			logger.Logger.Error("scan rows get top ten users db error", "error", err.Error())
+			logger.FromContext(ctx).Error("scan rows get top ten users db error", "error", err.Error())
 			return nil, err
 		}
 		userIDs = append(userIDs, id)
@@ -223,3 +363,129 @@ func (r *Repository) GetTopTenUsers(ctx context.Context, par string) ([]int, err
 
 	return userIDs, nil
 }
+
+func (r *Repository) GetAuthorCount(ctx context.Context, authorID int, par string) (int, error) {
+	querier := txs.GetQuerier(ctx, r.db)
+	if par != "likes" && par != "comments" && par != "views" {
+		logger.FromContext(ctx).Error(errors.InvalidTopParameterError{}.Error(), "par", par)
+		return 0, errors.InvalidTopParameterError{}
+	}
+
+	var count int
+	err := querier.QueryRowContext(ctx, fmt.Sprintf("SELECT %s FROM %s WHERE author_id = ?", netCount, par), authorID).Scan(&count)
+	if err != nil {
+		logger.FromContext(ctx).Error("query get author count db error", "error", err.Error(), "par", par)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *Repository) GetAuthorUniqueViewersCount(ctx context.Context, authorID int) (int, error) {
+	querier := txs.GetQuerier(ctx, r.db)
+	var count int
+	err := querier.QueryRowContext(ctx, "SELECT uniqExact(user_id) FROM views WHERE author_id = ?", authorID).Scan(&count)
+	if err != nil {
+		logger.FromContext(ctx).Error("query get author unique viewers count db error", "error", err.Error())
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *Repository) GetAuthorDynamic(ctx context.Context, authorID int, par string) ([]*models.Dynamic, error) {
+	querier := txs.GetQuerier(ctx, r.db)
+	if par != "likes" && par != "comments" && par != "views" {
+		logger.FromContext(ctx).Error(errors.InvalidTopParameterError{}.Error(), "par", par)
+		return nil, errors.InvalidTopParameterError{}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT 
+			toDate(time) as date,
+			%s as count
+		FROM %s
+		WHERE author_id = ?
+		GROUP BY date
+		ORDER BY date
+	`, netCount, par)
+
+	rows, err := querier.QueryContext(ctx, query, authorID)
+	if err != nil {
+		logger.FromContext(ctx).Error("query get author dynamic db error", "error", err.Error(), "par", par)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dynamics []*models.Dynamic
+	for rows.Next() {
+		var d models.Dynamic
+		if err := rows.Scan(&d.Date, &d.Count); err != nil {
+			logger.FromContext(ctx).Error("scan rows get author dynamic db error", "error", err.Error())
+			return nil, err
+		}
+		dynamics = append(dynamics, &d)
+	}
+
+	return dynamics, nil
+}
+
+func (r *Repository) GetAuthorTopPosts(ctx context.Context, authorID int, par string) ([]int, error) {
+	querier := txs.GetQuerier(ctx, r.db)
+	if par != "likes" && par != "comments" && par != "views" {
+		logger.FromContext(ctx).Error(errors.InvalidTopParameterError{}.Error(), "par", par)
+		return nil, errors.InvalidTopParameterError{}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT post_id
+		FROM %s
+		WHERE author_id = ?
+		GROUP BY post_id
+		ORDER BY %s DESC
+		LIMIT 10
+	`, par, netCount)
+
+	rows, err := querier.QueryContext(ctx, query, authorID)
+	if err != nil {
+		logger.FromContext(ctx).Error("query get author top posts db error", "error", err.Error(), "par", par)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var postIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			logger.FromContext(ctx).Error("scan rows get author top posts db error", "error", err.Error())
+			return nil, err
+		}
+		postIDs = append(postIDs, id)
+	}
+
+	return postIDs, nil
+}
+
+func (r *Repository) InsertEvents(ctx context.Context, par string, events []*models.Event) error {
+	querier := txs.GetQuerier(ctx, r.db)
+	if par != "likes" && par != "comments" && par != "views" {
+		logger.FromContext(ctx).Error(errors.InvalidTopParameterError{}.Error(), "par", par)
+		return errors.InvalidTopParameterError{}
+	}
+
+	stmt, err := querier.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s (event_id, time, user_id, post_id, author_id, sign)", par))
+	if err != nil {
+		logger.FromContext(ctx).Error("prepare insert events db error", "error", err.Error(), "par", par)
+		return err
+	}
+	defer stmt.Close()
+
+	for _, e := range events {
+		if _, err = stmt.ExecContext(ctx, e.EventId, e.Time.UTC(), int32(e.UserId), int32(e.PostId), int32(e.AuthorId), e.Sign); err != nil {
+			logger.FromContext(ctx).Error("exec insert events db error", "error", err.Error(), "par", par)
+			return err
+		}
+	}
+
+	return nil
+}
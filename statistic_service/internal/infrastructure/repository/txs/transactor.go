@@ -8,9 +8,10 @@ import (
 )
 
 type Querier interface {
-	Exec(sql string, arguments ...any) (sql.Result, error)
-	Query(sql string, args ...any) (*sql.Rows, error)
-	QueryRow(sql string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, sql string, arguments ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, sql string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, sql string, args ...any) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
 }
 
 type txKey struct{}
@@ -38,20 +39,20 @@ func NewTxBeginner(db *sql.DB) *TxBeginner {
 func (r *TxBeginner) WithTransaction(ctx context.Context, txFunc func(ctx context.Context) error) (err error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		logger.Logger.Error("error starting tx", "error", err.Error())
+		logger.FromContext(ctx).Error("error starting tx", "error", err.Error())
 		return err
 	}
 
 	defer func() {
 		if err != nil {
-			logger.Logger.Error(err.Error())
+			logger.FromContext(ctx).Error(err.Error())
 			err = errors.Join(err, tx.Rollback())
 		}
 	}()
 
 	err = txFunc(injectTx(ctx, tx))
 	if err != nil {
-		logger.Logger.Error(err.Error())
+		logger.FromContext(ctx).Error(err.Error())
 		return err
 	}
 
@@ -61,20 +62,20 @@ func (r *TxBeginner) WithTransaction(ctx context.Context, txFunc func(ctx contex
 func (r *TxBeginner) WithTransactionWithValue(ctx context.Context, txFunc func(ctx context.Context) (any, error)) (val any, err error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		logger.Logger.Error("error starting tx", "error", err.Error())
+		logger.FromContext(ctx).Error("error starting tx", "error", err.Error())
 		return nil, err
 	}
 
 	defer func() {
 		if err != nil {
-			logger.Logger.Error(err.Error())
+			logger.FromContext(ctx).Error(err.Error())
 			err = errors.Join(err, tx.Rollback())
 		}
 	}()
 
 	val, err = txFunc(injectTx(ctx, tx))
 	if err != nil {
-		logger.Logger.Error(err.Error())
+		logger.FromContext(ctx).Error(err.Error())
 		return nil, err
 	}
 
@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"context"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/config"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/errors"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+)
+
+const ServiceName = "statistic-service"
+
+// NewTracerProvider sets the global tracer provider and the W3C trace context propagator,
+// spans are flushed when the application stops.
+func NewTracerProvider(lc fx.Lifecycle, cfg *config.Config) (trace.TracerProvider, error) {
+	exporter, err := newExporter(cfg)
+	if err != nil {
+		logger.Logger.Error("error creating trace exporter", "error", err.Error())
+		return nil, err
+	}
+
+	tp := NewProvider(exporter, cfg.TracingSampleRatio)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return tp.Shutdown(ctx)
+		},
+	})
+
+	return tp, nil
+}
+
+// NewProvider samples ratio of the new traces and exports them with exporter, the kafka tests
+// pass a tracetest.InMemoryExporter. A nil exporter only propagates the trace context.
+func NewProvider(exporter sdktrace.SpanExporter, ratio float64) *sdktrace.TracerProvider {
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	}
+	if exporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	return sdktrace.NewTracerProvider(opts...)
+}
+
+func newExporter(cfg *config.Config) (sdktrace.SpanExporter, error) {
+	switch cfg.TracingExporter {
+	case "otlp":
+		return otlptracegrpc.New(context.Background(),
+			otlptracegrpc.WithEndpoint(cfg.TracingOTLPEndpoint),
+			otlptracegrpc.WithInsecure())
+	case "stdout":
+		return stdouttrace.New()
+	case "none":
+		return nil, nil
+	default:
+		return nil, errors.UnknownTracingExporterError{Exporter: cfg.TracingExporter}
+	}
+}
@@ -8,24 +8,48 @@ import (
 	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/application"
 	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/config"
 	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/metrics"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
 	"net"
 )
 
-func NewServer(s *application.StatisticServiceApp, cfg *config.Config) (*grpc.Server, net.Listener) {
+func NewServer(s *application.StatisticServiceApp, cfg *config.Config, tp trace.TracerProvider, m *metrics.GRPCMetrics,
+	hs *grpchealth.Server) (*grpc.Server, net.Listener) {
 	lis, err := net.Listen("tcp", fmt.Sprintf("%s%s", cfg.StatisticServiceHost, cfg.StatisticServicePort))
 	if err != nil {
 		logger.Logger.Error(fmt.Sprintf("failed to listen: %s", err.Error()))
 		return nil, nil
 	}
 
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithTracerProvider(tp))),
+		grpc.ChainUnaryInterceptor(requestContext, m.UnaryServerInterceptor()))
 	pb.RegisterStatisticServiceServer(grpcServer, s)
+	healthpb.RegisterHealthServer(grpcServer, hs)
 
 	return grpcServer, lis
 }
 
+// requestContext puts the request ID and the user passed by the gateway in the metadata into
+// the context of the call, so that they are attached to the logs and the events it sends.
+func requestContext(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get(logger.RequestIDKey); len(v) > 0 {
+		ctx = logger.WithRequestID(ctx, v[0])
+	}
+	if v := md.Get("user_id"); len(v) == 1 && v[0] != "" {
+		ctx = logger.WithUserID(ctx, v[0])
+	}
+
+	return handler(ctx, req)
+}
+
 func RunServer(lc fx.Lifecycle, grpcServer *grpc.Server, listener net.Listener) error {
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
@@ -3,35 +3,62 @@ package service
 import (
 	"context"
 	pb "github.com/grigorovskiiy/soa-hse/protos"
+	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/errors"
 	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/logger"
 	"github.com/grigorovskiiy/soa-hse/statistic_service/internal/infrastructure/models"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+const (
+	// topPostsPage is how many posts of the top GetTopTenPosts reads at a time, some of them
+	// may be private posts of other users or deleted ones.
+	topPostsPage = 30
+	// topPostsPages bounds the posts GetTopTenPosts checks, the top may be shorter than ten if
+	// most of them are hidden.
+	topPostsPages = 10
+)
+
 type StatisticRepository interface {
 	GetViewsCount(ctx context.Context, postID int) (int, error)
 	GetCommentsCount(ctx context.Context, postID int) (int, error)
 	GetLikesCount(ctx context.Context, postID int) (int, error)
+	GetPostsStats(ctx context.Context, postIDs []int) ([]*models.PostStats, error)
 	GetViewsDynamic(ctx context.Context, postID int) ([]*models.Dynamic, error)
 	GetCommentsDynamic(ctx context.Context, postID int) ([]*models.Dynamic, error)
 	GetLikesDynamic(ctx context.Context, postID int) ([]*models.Dynamic, error)
-	GetTopTenPosts(ctx context.Context, par string) ([]int, error)
+	GetUniqueViewersCount(ctx context.Context, postID int) (int, error)
+	GetUniqueLikersCount(ctx context.Context, postID int) (int, error)
+	GetUniqueViewersDynamic(ctx context.Context, postID int) ([]*models.Dynamic, error)
+	GetUniqueLikersDynamic(ctx context.Context, postID int) ([]*models.Dynamic, error)
+	GetTopPosts(ctx context.Context, par string, limit, offset int) ([]int, error)
 	GetTopTenUsers(ctx context.Context, par string) ([]int, error)
+	GetAuthorCount(ctx context.Context, authorID int, par string) (int, error)
+	GetAuthorUniqueViewersCount(ctx context.Context, authorID int) (int, error)
+	GetAuthorDynamic(ctx context.Context, authorID int, par string) ([]*models.Dynamic, error)
+	GetAuthorTopPosts(ctx context.Context, authorID int, par string) ([]int, error)
+	InsertEvents(ctx context.Context, par string, events []*models.Event) error
 }
 
 type Transactor interface {
 	WithTransaction(context.Context, func(context.Context) error) error
 	WithTransactionWithValue(context.Context, func(context.Context) (any, error)) (any, error)
 }
+type PostsClient interface {
+	GetPostMeta(ctx context.Context, postID int32) (*pb.PostMetaResponse, error)
+	GetPostsMeta(ctx context.Context, postIDs []int32) (map[int32]*pb.PostMetaResponse, error)
+}
+
 type Service struct {
 	repository StatisticRepository
 	tr         Transactor
+	posts      PostsClient
 }
 
-func NewService(repository StatisticRepository, tr Transactor) *Service {
+func NewService(repository StatisticRepository, tr Transactor, posts PostsClient) *Service {
 	return &Service{
 		repository: repository,
 		tr:         tr,
+		posts:      posts,
 	}
 }
 
@@ -39,7 +66,7 @@ func (s *Service) GetViewsCount(ctx context.Context, p *pb.PostID) (*pb.CountRes
 	count, err := s.tr.WithTransactionWithValue(ctx, func(ctx context.Context) (any, error) {
 		count, err := s.repository.GetViewsCount(ctx, int(p.PostId))
 		if err != nil {
-			logger.Logger.Error("get views count error", "error", err.Error())
+			logger.FromContext(ctx).Error("get views count error", "error", err.Error())
 			return nil, err
 		}
 
@@ -47,7 +74,7 @@ func (s *Service) GetViewsCount(ctx context.Context, p *pb.PostID) (*pb.CountRes
 	})
 
 	if err != nil {
-		logger.Logger.Error("get views count error", "error", err.Error())
+		logger.FromContext(ctx).Error("get views count error", "error", err.Error())
 		return nil, err
 	}
 
@@ -58,7 +85,7 @@ func (s *Service) GetCommentsCount(ctx context.Context, p *pb.PostID) (*pb.Count
 	count, err := s.tr.WithTransactionWithValue(ctx, func(ctx context.Context) (any, error) {
 		count, err := s.repository.GetCommentsCount(ctx, int(p.PostId))
 		if err != nil {
-			logger.Logger.Error("get comments count error", "error", err.Error())
+			logger.FromContext(ctx).Error("get comments count error", "error", err.Error())
 			return nil, err
 		}
 
@@ -66,7 +93,7 @@ func (s *Service) GetCommentsCount(ctx context.Context, p *pb.PostID) (*pb.Count
 	})
 
 	if err != nil {
-		logger.Logger.Error("get comments count error", "error", err.Error())
+		logger.FromContext(ctx).Error("get comments count error", "error", err.Error())
 		return nil, err
 	}
 
@@ -77,7 +104,7 @@ func (s *Service) GetLikesCount(ctx context.Context, p *pb.PostID) (*pb.CountRes
 	count, err := s.tr.WithTransactionWithValue(ctx, func(ctx context.Context) (any, error) {
 		count, err := s.repository.GetLikesCount(ctx, int(p.PostId))
 		if err != nil {
-			logger.Logger.Error("get likes count error", "error", err.Error())
+			logger.FromContext(ctx).Error("get likes count error", "error", err.Error())
 			return nil, err
 		}
 
@@ -85,7 +112,7 @@ func (s *Service) GetLikesCount(ctx context.Context, p *pb.PostID) (*pb.CountRes
 	})
 
 	if err != nil {
-		logger.Logger.Error("get likes count error", "error", err.Error())
+		logger.FromContext(ctx).Error("get likes count error", "error", err.Error())
 		return nil, err
 	}
 
@@ -96,14 +123,14 @@ func (s *Service) GetViewsDynamic(ctx context.Context, p *pb.PostID) (*pb.Dynami
 	dbDyn, err := s.tr.WithTransactionWithValue(ctx, func(ctx context.Context) (any, error) {
 		dbDyn, err := s.repository.GetViewsDynamic(ctx, int(p.PostId))
 		if err != nil {
-			logger.Logger.Error("get views dynamic error", "error", err.Error())
+			logger.FromContext(ctx).Error("get views dynamic error", "error", err.Error())
 			return nil, err
 		}
 
 		return dbDyn, nil
 	})
 	if err != nil {
-		logger.Logger.Error("get views dynamic error", "error", err.Error())
+		logger.FromContext(ctx).Error("get views dynamic error", "error", err.Error())
 		return nil, err
 	}
 
@@ -122,14 +149,14 @@ func (s *Service) GetCommentsDynamic(ctx context.Context, p *pb.PostID) (*pb.Dyn
 	dbDyn, err := s.tr.WithTransactionWithValue(ctx, func(ctx context.Context) (any, error) {
 		dbDyn, err := s.repository.GetCommentsDynamic(ctx, int(p.PostId))
 		if err != nil {
-			logger.Logger.Error("get comments dynamic error", "error", err.Error())
+			logger.FromContext(ctx).Error("get comments dynamic error", "error", err.Error())
 			return nil, err
 		}
 
 		return dbDyn, nil
 	})
 	if err != nil {
-		logger.Logger.Error("get comments dynamic error", "error", err.Error())
+		logger.FromContext(ctx).Error("get comments dynamic error", "error", err.Error())
 		return nil, err
 	}
 
@@ -148,14 +175,14 @@ func (s *Service) GetLikesDynamic(ctx context.Context, p *pb.PostID) (*pb.Dynami
 	dbDyn, err := s.tr.WithTransactionWithValue(ctx, func(ctx context.Context) (any, error) {
 		dbDyn, err := s.repository.GetLikesDynamic(ctx, int(p.PostId))
 		if err != nil {
-			logger.Logger.Error("get likes dynamic error", "error", err.Error())
+			logger.FromContext(ctx).Error("get likes dynamic error", "error", err.Error())
 			return nil, err
 		}
 
 		return dbDyn, nil
 	})
 	if err != nil {
-		logger.Logger.Error("get likes dynamic error", "error", err.Error())
+		logger.FromContext(ctx).Error("get likes dynamic error", "error", err.Error())
 		return nil, err
 	}
 
@@ -170,25 +197,137 @@ func (s *Service) GetLikesDynamic(ctx context.Context, p *pb.PostID) (*pb.Dynami
 	return &pbDyn, nil
 }
 
-func (s *Service) GetTopTenPosts(ctx context.Context, p *pb.TopTenParameter) (*pb.TopTenPostsResponse, error) {
-	dbPosts, err := s.tr.WithTransactionWithValue(ctx, func(ctx context.Context) (any, error) {
-		dbPosts, err := s.repository.GetTopTenPosts(ctx, p.GetPar())
+func (s *Service) GetUniqueViewersCount(ctx context.Context, p *pb.PostID) (*pb.CountResponse, error) {
+	count, err := s.tr.WithTransactionWithValue(ctx, func(ctx context.Context) (any, error) {
+		count, err := s.repository.GetUniqueViewersCount(ctx, int(p.PostId))
 		if err != nil {
-			logger.Logger.Error("get top ten posts error", "error", err.Error())
+			logger.FromContext(ctx).Error("get unique viewers count error", "error", err.Error())
 			return nil, err
 		}
 
-		return dbPosts, nil
+		return count, nil
 	})
+
 	if err != nil {
-		logger.Logger.Error("get top ten posts error", "error", err.Error())
+		logger.FromContext(ctx).Error("get unique viewers count error", "error", err.Error())
 		return nil, err
 	}
 
-	posts := dbPosts.([]int)
+	return &pb.CountResponse{Count: int32(count.(int))}, nil
+}
+
+func (s *Service) GetUniqueLikersCount(ctx context.Context, p *pb.PostID) (*pb.CountResponse, error) {
+	count, err := s.tr.WithTransactionWithValue(ctx, func(ctx context.Context) (any, error) {
+		count, err := s.repository.GetUniqueLikersCount(ctx, int(p.PostId))
+		if err != nil {
+			logger.FromContext(ctx).Error("get unique likers count error", "error", err.Error())
+			return nil, err
+		}
+
+		return count, nil
+	})
+
+	if err != nil {
+		logger.FromContext(ctx).Error("get unique likers count error", "error", err.Error())
+		return nil, err
+	}
+
+	return &pb.CountResponse{Count: int32(count.(int))}, nil
+}
+
+func (s *Service) GetUniqueViewersDynamic(ctx context.Context, p *pb.PostID) (*pb.DynamicListResponse, error) {
+	dbDyn, err := s.tr.WithTransactionWithValue(ctx, func(ctx context.Context) (any, error) {
+		dbDyn, err := s.repository.GetUniqueViewersDynamic(ctx, int(p.PostId))
+		if err != nil {
+			logger.FromContext(ctx).Error("get unique viewers dynamic error", "error", err.Error())
+			return nil, err
+		}
+
+		return dbDyn, nil
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("get unique viewers dynamic error", "error", err.Error())
+		return nil, err
+	}
+
+	dyn := dbDyn.([]*models.Dynamic)
+	pbDyn := pb.DynamicListResponse{Dynamic: make([]*pb.DynamicResponse, len(dyn))}
+	for i := range dyn {
+		pbDyn.Dynamic[i] = &pb.DynamicResponse{
+			Count: &pb.CountResponse{Count: int32(dyn[i].Count)}, Data: timestamppb.New(dyn[i].Date),
+		}
+	}
+
+	return &pbDyn, nil
+}
+
+func (s *Service) GetUniqueLikersDynamic(ctx context.Context, p *pb.PostID) (*pb.DynamicListResponse, error) {
+	dbDyn, err := s.tr.WithTransactionWithValue(ctx, func(ctx context.Context) (any, error) {
+		dbDyn, err := s.repository.GetUniqueLikersDynamic(ctx, int(p.PostId))
+		if err != nil {
+			logger.FromContext(ctx).Error("get unique likers dynamic error", "error", err.Error())
+			return nil, err
+		}
+
+		return dbDyn, nil
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("get unique likers dynamic error", "error", err.Error())
+		return nil, err
+	}
+
+	dyn := dbDyn.([]*models.Dynamic)
+	pbDyn := pb.DynamicListResponse{Dynamic: make([]*pb.DynamicResponse, len(dyn))}
+	for i := range dyn {
+		pbDyn.Dynamic[i] = &pb.DynamicResponse{
+			Count: &pb.CountResponse{Count: int32(dyn[i].Count)}, Data: timestamppb.New(dyn[i].Date),
+		}
+	}
+
+	return &pbDyn, nil
+}
+
+// GetTopTenPosts returns the ten posts with the most par that userID may see. The top is read
+// topPostsPage posts at a time until ten of them are visible, at most topPostsPages times.
+func (s *Service) GetTopTenPosts(ctx context.Context, p *pb.TopTenParameter, userID int32) (*pb.TopTenPostsResponse, error) {
+	var posts []int32
+	for page := 0; page < topPostsPages && len(posts) < 10; page++ {
+		dbPosts, err := s.tr.WithTransactionWithValue(ctx, func(ctx context.Context) (any, error) {
+			dbPosts, err := s.repository.GetTopPosts(ctx, p.GetPar(), topPostsPage, page*topPostsPage)
+			if err != nil {
+				logger.FromContext(ctx).Error("get top ten posts error", "error", err.Error())
+				return nil, err
+			}
+
+			return dbPosts, nil
+		})
+		if err != nil {
+			logger.FromContext(ctx).Error("get top ten posts error", "error", err.Error())
+			return nil, err
+		}
+
+		candidates := dbPosts.([]int)
+		ids := make([]int32, len(candidates))
+		for i := range candidates {
+			ids[i] = int32(candidates[i])
+		}
+
+		visible, err := s.VisiblePosts(ctx, ids, userID)
+		if err != nil {
+			logger.FromContext(ctx).Error("get visible top posts error", "error", err.Error())
+			return nil, err
+		}
+		posts = append(posts, visible...)
+
+		if len(candidates) < topPostsPage {
+			break
+		}
+	}
+
+	posts = posts[:min(len(posts), 10)]
 	pbPosts := pb.TopTenPostsResponse{Posts: make([]*pb.PostID, len(posts))}
 	for i := range posts {
-		pbPosts.Posts[i] = &pb.PostID{PostId: int32(posts[i])}
+		pbPosts.Posts[i] = &pb.PostID{PostId: posts[i]}
 	}
 
 	return &pbPosts, nil
@@ -198,14 +337,14 @@ func (s *Service) GetTopTenUsers(ctx context.Context, p *pb.TopTenParameter) (*p
 	dbUsers, err := s.tr.WithTransactionWithValue(ctx, func(ctx context.Context) (any, error) {
 		dbUsers, err := s.repository.GetTopTenUsers(ctx, p.GetPar())
 		if err != nil {
-			logger.Logger.Error("get top ten users error", "error", err.Error())
+			logger.FromContext(ctx).Error("get top ten users error", "error", err.Error())
 			return nil, err
 		}
 
 		return dbUsers, nil
 	})
 	if err != nil {
-		logger.Logger.Error("get top ten users error", "error", err.Error())
+		logger.FromContext(ctx).Error("get top ten users error", "error", err.Error())
 		return nil, err
 	}
 
@@ -217,3 +356,179 @@ func (s *Service) GetTopTenUsers(ctx context.Context, p *pb.TopTenParameter) (*p
 
 	return &pbUsers, nil
 }
+
+func (s *Service) GetAuthorStats(ctx context.Context, p *pb.UserID) (*pb.AuthorStatsResponse, error) {
+	dbStats, err := s.tr.WithTransactionWithValue(ctx, func(ctx context.Context) (any, error) {
+		authorID := int(p.UserId)
+		var stats models.AuthorStats
+		var err error
+
+		counts := map[string]*int{"views": &stats.ViewsCount, "likes": &stats.LikesCount, "comments": &stats.CommentsCount}
+		dynamics := map[string]*[]*models.Dynamic{"views": &stats.ViewsDynamic, "likes": &stats.LikesDynamic, "comments": &stats.CommentsDynamic}
+		tops := map[string]*[]int{"views": &stats.TopPostsByViews, "likes": &stats.TopPostsByLikes, "comments": &stats.TopPostsByComments}
+
+		for par := range counts {
+			if *counts[par], err = s.repository.GetAuthorCount(ctx, authorID, par); err != nil {
+				logger.FromContext(ctx).Error("get author count error", "error", err.Error(), "par", par)
+				return nil, err
+			}
+			if *dynamics[par], err = s.repository.GetAuthorDynamic(ctx, authorID, par); err != nil {
+				logger.FromContext(ctx).Error("get author dynamic error", "error", err.Error(), "par", par)
+				return nil, err
+			}
+			if *tops[par], err = s.repository.GetAuthorTopPosts(ctx, authorID, par); err != nil {
+				logger.FromContext(ctx).Error("get author top posts error", "error", err.Error(), "par", par)
+				return nil, err
+			}
+		}
+
+		if stats.UniqueViewersCount, err = s.repository.GetAuthorUniqueViewersCount(ctx, authorID); err != nil {
+			logger.FromContext(ctx).Error("get author unique viewers count error", "error", err.Error())
+			return nil, err
+		}
+
+		return &stats, nil
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("get author stats error", "error", err.Error())
+		return nil, err
+	}
+
+	stats := dbStats.(*models.AuthorStats)
+	pbStats := pb.AuthorStatsResponse{
+		ViewsCount:         int32(stats.ViewsCount),
+		LikesCount:         int32(stats.LikesCount),
+		CommentsCount:      int32(stats.CommentsCount),
+		UniqueViewersCount: int32(stats.UniqueViewersCount),
+		ViewsDynamic:       toPbDynamic(stats.ViewsDynamic),
+		LikesDynamic:       toPbDynamic(stats.LikesDynamic),
+		CommentsDynamic:    toPbDynamic(stats.CommentsDynamic),
+		TopPostsByViews:    toPbPostIDs(stats.TopPostsByViews),
+		TopPostsByLikes:    toPbPostIDs(stats.TopPostsByLikes),
+		TopPostsByComments: toPbPostIDs(stats.TopPostsByComments),
+	}
+	if stats.ViewsCount > 0 {
+		pbStats.EngagementRate = float64(stats.LikesCount+stats.CommentsCount) / float64(stats.ViewsCount)
+	}
+
+	return &pbStats, nil
+}
+
+// BatchGetPostStats returns counters of every requested post in the request order,
+// posts without events get zero counters.
+func (s *Service) BatchGetPostStats(ctx context.Context, p *pb.BatchPostStatsRequest) (*pb.BatchPostStatsResponse, error) {
+	seen := make(map[int32]bool, len(p.PostIds))
+	postIDs := make([]int, 0, len(p.PostIds))
+	for _, id := range p.PostIds {
+		if !seen[id] {
+			seen[id] = true
+			postIDs = append(postIDs, int(id))
+		}
+	}
+	if len(postIDs) == 0 {
+		return &pb.BatchPostStatsResponse{}, nil
+	}
+
+	dbStats, err := s.tr.WithTransactionWithValue(ctx, func(ctx context.Context) (any, error) {
+		stats, err := s.repository.GetPostsStats(ctx, postIDs)
+		if err != nil {
+			logger.FromContext(ctx).Error("get posts stats error", "error", err.Error())
+			return nil, err
+		}
+
+		return stats, nil
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("batch get post stats error", "error", err.Error())
+		return nil, err
+	}
+
+	byPost := make(map[int]*models.PostStats)
+	for _, stats := range dbStats.([]*models.PostStats) {
+		byPost[stats.PostId] = stats
+	}
+
+	pbStats := pb.BatchPostStatsResponse{Stats: make([]*pb.PostStats, len(postIDs))}
+	for i, id := range postIDs {
+		pbStats.Stats[i] = &pb.PostStats{PostId: int32(id)}
+		if stats, ok := byPost[id]; ok {
+			pbStats.Stats[i].ViewsCount = int32(stats.ViewsCount)
+			pbStats.Stats[i].LikesCount = int32(stats.LikesCount)
+			pbStats.Stats[i].CommentsCount = int32(stats.CommentsCount)
+		}
+	}
+
+	return &pbStats, nil
+}
+
+func (s *Service) SaveEvents(ctx context.Context, par string, events []*models.Event) error {
+	err := s.tr.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repository.InsertEvents(ctx, par, events); err != nil {
+			logger.FromContext(ctx).Error("insert events error", "error", err.Error(), "par", par)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("save events error", "error", err.Error(), "par", par)
+		return err
+	}
+
+	return nil
+}
+
+func toPbDynamic(dyn []*models.Dynamic) []*pb.DynamicResponse {
+	pbDyn := make([]*pb.DynamicResponse, len(dyn))
+	for i := range dyn {
+		pbDyn[i] = &pb.DynamicResponse{
+			Count: &pb.CountResponse{Count: int32(dyn[i].Count)}, Data: timestamppb.New(dyn[i].Date),
+		}
+	}
+
+	return pbDyn
+}
+
+func toPbPostIDs(posts []int) []*pb.PostID {
+	pbPosts := make([]*pb.PostID, len(posts))
+	for i := range posts {
+		pbPosts[i] = &pb.PostID{PostId: int32(posts[i])}
+	}
+
+	return pbPosts
+}
+
+// CheckPostAccess allows everyone to see statistics of public posts and only the author to
+// see statistics of private ones.
+func (s *Service) CheckPostAccess(ctx context.Context, postID int32, userID int32) error {
+	meta, err := s.posts.GetPostMeta(ctx, postID)
+	if err != nil {
+		return err
+	}
+
+	if meta.SecurityFlag && meta.AuthorId != userID {
+		return errors.PostAccessDeniedError{}
+	}
+
+	return nil
+}
+
+// VisiblePosts keeps the posts whose statistics userID may see, see CheckPostAccess. Deleted
+// posts are dropped as well.
+func (s *Service) VisiblePosts(ctx context.Context, postIDs []int32, userID int32) ([]int32, error) {
+	metas, err := s.posts.GetPostsMeta(ctx, postIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]int32, 0, len(postIDs))
+	for _, id := range postIDs {
+		meta, ok := metas[id]
+		if !ok || meta.SecurityFlag && meta.AuthorId != userID {
+			continue
+		}
+		visible = append(visible, id)
+	}
+
+	return visible, nil
+}
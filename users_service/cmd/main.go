@@ -4,9 +4,13 @@ import (
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/application"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/config"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/db"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/health"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/kafka"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/loginguard"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/metrics"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/repository"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/tracing"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/server"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/service/eventsservice"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/service/usersservice"
@@ -23,11 +27,15 @@ func init() {
 func main() {
 	addOpts := fx.Options(
 		fx.Provide(config.NewConfig),
+		fx.Provide(tracing.NewTracerProvider),
+		metrics.Module,
+		health.Module,
 		fx.Provide(db.InitDb),
 		fx.Provide(repository.NewUsersRepository),
 		fx.Provide(func(r *repository.UsersRepository) usersservice.Repository {
 			return r
 		}),
+		loginguard.Module,
 		fx.Provide(usersservice.NewUService),
 		fx.Provide(func(s *usersservice.UService) application.UsersService {
 			return s
@@ -37,9 +45,15 @@ func main() {
 		fx.Provide(func(s *eventsservice.KafkaService) application.EventsService {
 			return s
 		}),
+		fx.Provide(func(s *eventsservice.KafkaService) usersservice.EventsService {
+			return s
+		}),
 		fx.Provide(application.NewUsersApp),
+		fx.Provide(application.NewUsersServiceApp),
 		fx.Provide(server.NewServer),
+		fx.Provide(server.NewGRPCServer),
 		fx.Invoke(server.RunServer),
+		fx.Invoke(server.RunGRPCServer),
 	)
 
 	fx.New(addOpts).Run()
@@ -3,32 +3,42 @@ package application
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	pb "github.com/grigorovskiiy/soa-hse/protos"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/config"
+	usersErrors "github.com/grigorovskiiy/soa-hse/users_service/internal/errors"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/metrics"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/models"
 	"io"
+	"math"
 	"net/http"
-	"time"
+	"strconv"
 )
 
 type UsersService interface {
-	Register(*models.RegisterRequest) (int, error)
-	Login(*models.GetLoginRequest) (string, error)
-	UpdateUserInfo(*models.UserUpdateRequest, string) error
-	GetUserInfo(string) (*models.DbUser, error)
+	Register(context.Context, *models.RegisterRequest) (int, error)
+	Login(context.Context, *models.GetLoginRequest) (string, error)
+	UpdateUserInfo(context.Context, *models.UserUpdateRequest, string) error
+	GetUserInfo(context.Context, string) (*models.DbUser, error)
+	GetUser(context.Context, int32) (*models.DbUser, error)
+	BatchGetUsers(context.Context, []int32) ([]*models.DbUser, error)
+	UpdateUser(context.Context, *models.UserUpdateRequest, int32) (*models.DbUser, error)
 }
 
 type EventsService interface {
-	SendEvent(context.Context, string, any) error
+	PublishClientRegistered(context.Context, string, *pb.ClientRegisteredEvent) error
+	PublishClientUpdated(context.Context, string, *pb.ClientUpdatedEvent) error
 }
 type UsersApp struct {
 	UsersService  UsersService
 	EventsService EventsService
 	cfg           *config.Config
+	metrics       *metrics.UsersMetrics
 }
 
-func NewUsersApp(uS UsersService, eS EventsService, cfg *config.Config) *UsersApp {
-	return &UsersApp{UsersService: uS, EventsService: eS, cfg: cfg}
+func NewUsersApp(uS UsersService, eS EventsService, cfg *config.Config, m *metrics.UsersMetrics) *UsersApp {
+	return &UsersApp{UsersService: uS, EventsService: eS, cfg: cfg, metrics: m}
 }
 
 func writeRes(w http.ResponseWriter, code int, val any) {
@@ -38,7 +48,7 @@ func writeRes(w http.ResponseWriter, code int, val any) {
 }
 
 func (a *UsersApp) Register(w http.ResponseWriter, r *http.Request) {
-	logger := logger.Logger.With("path", r.URL.Path, "method", r.Method)
+	logger := logger.FromContext(r.Context()).With("path", r.URL.Path, "method", r.Method)
 	d, err := io.ReadAll(r.Body)
 	if err != nil {
 		logger.Error("read body error", "error", err.Error())
@@ -53,14 +63,15 @@ func (a *UsersApp) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userID, err := a.UsersService.Register(&req)
+	userID, err := a.UsersService.Register(r.Context(), &req)
 	if err != nil {
 		logger.Error("service register error", "error", err.Error())
 		writeRes(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	a.metrics.Registrations.Inc()
 
-	if err = a.EventsService.SendEvent(context.Background(), a.cfg.ClientsTopic, models.ClientUpdate{UserId: userID, Time: time.Now()}); err != nil {
+	if err = a.EventsService.PublishClientRegistered(r.Context(), a.cfg.ClientsTopic, &pb.ClientRegisteredEvent{UserId: int32(userID)}); err != nil {
 		logger.Error("register send event error", "error", err.Error())
 		writeRes(w, http.StatusInternalServerError, err.Error())
 	}
@@ -69,7 +80,7 @@ func (a *UsersApp) Register(w http.ResponseWriter, r *http.Request) {
 }
 
 func (a *UsersApp) Login(w http.ResponseWriter, r *http.Request) {
-	logger := logger.Logger.With("path", r.URL.Path, "method", r.Method)
+	logger := logger.FromContext(r.Context()).With("path", r.URL.Path, "method", r.Method)
 
 	d, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -86,18 +97,29 @@ func (a *UsersApp) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := a.UsersService.Login(&req)
+	req.IP = ClientIP(r, a.cfg.TrustProxyHeaders)
+	token, err := a.UsersService.Login(r.Context(), &req)
+	var locked usersErrors.LoginLockedError
+	if errors.As(err, &locked) {
+		logger.Warn("service login locked", "error", err.Error())
+		a.metrics.Logins.WithLabelValues("locked").Inc()
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+		writeRes(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
 	if err != nil {
 		logger.Error("service login error", "error", err.Error())
+		a.metrics.Logins.WithLabelValues("failure").Inc()
 		writeRes(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	a.metrics.Logins.WithLabelValues("success").Inc()
 
 	writeRes(w, http.StatusOK, token)
 }
 
 func (a *UsersApp) UpdateUserInfo(w http.ResponseWriter, r *http.Request) {
-	logger := logger.Logger.With("path", r.URL.Path, "method", r.Method)
+	logger := logger.FromContext(r.Context()).With("path", r.URL.Path, "method", r.Method)
 
 	d, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -115,21 +137,39 @@ func (a *UsersApp) UpdateUserInfo(w http.ResponseWriter, r *http.Request) {
 	}
 	login := r.Header.Get("Login")
 
-	err = a.UsersService.UpdateUserInfo(&req, login)
+	err = a.UsersService.UpdateUserInfo(r.Context(), &req, login)
 	if err != nil {
 		logger.Error("service update error", "error", err.Error())
 		writeRes(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
+	user, err := a.UsersService.GetUserInfo(r.Context(), login)
+	if err != nil {
+		logger.Error("service get user info error", "error", err.Error())
+		writeRes(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err = a.EventsService.PublishClientUpdated(r.Context(), a.cfg.UsersTopic, &pb.ClientUpdatedEvent{
+		UserId:  int32(user.Id),
+		Name:    user.Name,
+		Surname: user.Surname,
+		Email:   user.Email,
+	}); err != nil {
+		logger.Error("update send event error", "error", err.Error())
+		writeRes(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	writeRes(w, http.StatusOK, "user is updated")
 }
 
 func (a *UsersApp) GetUserInfo(w http.ResponseWriter, r *http.Request) {
-	logger := logger.Logger.With("path", r.URL.Path, "method", r.Method)
+	logger := logger.FromContext(r.Context()).With("path", r.URL.Path, "method", r.Method)
 	login := r.Header.Get("Login")
 
-	user, err := a.UsersService.GetUserInfo(login)
+	user, err := a.UsersService.GetUserInfo(r.Context(), login)
 	if err != nil {
 		logger.Error("service get user info error", "error", err.Error())
 		writeRes(w, http.StatusInternalServerError, err.Error())
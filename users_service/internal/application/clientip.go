@@ -0,0 +1,60 @@
+package application
+
+import (
+	"context"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ForwardedForHeader is appended with the address of its client by the gateway proxy and by
+// grpc-gateway, which passes it as the x-forwarded-for metadata.
+const ForwardedForHeader = "X-Forwarded-For"
+
+// ClientIP is the address of the client. users_service is called through the gateway, so the
+// last X-Forwarded-For address is the client when the proxy headers are trusted, the addresses
+// before it are sent by the client and may be forged.
+func ClientIP(r *http.Request, trustProxyHeaders bool) string {
+	if trustProxyHeaders {
+		if ip := lastForwarded(r.Header.Values(ForwardedForHeader)); ip != "" {
+			return ip
+		}
+	}
+
+	return host(r.RemoteAddr)
+}
+
+// grpcClientIP is ClientIP of a gRPC request.
+func grpcClientIP(ctx context.Context, trustProxyHeaders bool) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok && trustProxyHeaders {
+		if ip := lastForwarded(md.Get(ForwardedForHeader)); ip != "" {
+			return ip
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return host(p.Addr.String())
+	}
+
+	return ""
+}
+
+func lastForwarded(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	addrs := strings.Split(values[len(values)-1], ",")
+	return strings.TrimSpace(addrs[len(addrs)-1])
+}
+
+func host(addr string) string {
+	h, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	return h
+}
@@ -0,0 +1,227 @@
+package application
+
+import (
+	"context"
+	"errors"
+	pb "github.com/grigorovskiiy/soa-hse/protos"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/config"
+	usersErrors "github.com/grigorovskiiy/soa-hse/users_service/internal/errors"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/metrics"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"strconv"
+)
+
+// UsersServiceApp serves pb.UsersService next to the HTTP handlers of UsersApp.
+type UsersServiceApp struct {
+	pb.UnimplementedUsersServiceServer
+	UsersService  UsersService
+	EventsService EventsService
+	cfg           *config.Config
+	metrics       *metrics.UsersMetrics
+}
+
+func NewUsersServiceApp(uS UsersService, eS EventsService, cfg *config.Config, m *metrics.UsersMetrics) *UsersServiceApp {
+	return &UsersServiceApp{UsersService: uS, EventsService: eS, cfg: cfg, metrics: m}
+}
+
+func toUserResponse(user *models.DbUser) *pb.UserResponse {
+	return &pb.UserResponse{
+		UserId:    int32(user.Id),
+		Login:     user.Login,
+		Name:      user.Name,
+		Surname:   user.Surname,
+		Email:     user.Email,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+		UpdatedAt: timestamppb.New(user.UpdatedAt),
+	}
+}
+
+func newUserID(id int) *pb.UserID {
+	return &pb.UserID{UserId: int32(id)}
+}
+
+func newClientUpdatedEvent(user *models.DbUser) *pb.ClientUpdatedEvent {
+	return &pb.ClientUpdatedEvent{
+		UserId:  int32(user.Id),
+		Name:    user.Name,
+		Surname: user.Surname,
+		Email:   user.Email,
+	}
+}
+
+func grpcError(err error) error {
+	switch {
+	case errors.As(err, &usersErrors.UserNotFoundError{}):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.As(err, &usersErrors.LoginError{}):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.As(err, &usersErrors.LoginLockedError{}):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.As(err, &usersErrors.AlreadyRegisteredError{}):
+		return status.Error(codes.AlreadyExists, err.Error())
+	default:
+		return err
+	}
+}
+
+func (s *UsersServiceApp) Register(ctx context.Context, pb *pb.RegisterRequest) (*pb.UserID, error) {
+	logger := logger.FromContext(ctx).With("method", "Register")
+	logger.Info("users grpc request started")
+
+	userID, err := s.UsersService.Register(ctx, &models.RegisterRequest{
+		Login:    pb.Login,
+		Email:    pb.Email,
+		Password: pb.Password,
+	})
+	if err != nil {
+		logger.Error("register error", "error", err.Error())
+		return nil, grpcError(err)
+	}
+	s.metrics.Registrations.Inc()
+
+	if err = s.EventsService.PublishClientRegistered(ctx, s.cfg.ClientsTopic, newClientRegisteredEvent(userID)); err != nil {
+		logger.Error("register send event error", "error", err.Error())
+		return nil, err
+	}
+
+	logger.Info("users grpc request completed")
+	return newUserID(userID), nil
+}
+
+func (s *UsersServiceApp) Login(ctx context.Context, pb *pb.LoginRequest) (*pb.LoginResponse, error) {
+	logger := logger.FromContext(ctx).With("method", "Login")
+	logger.Info("users grpc request started")
+
+	req := &models.GetLoginRequest{Login: pb.Login, Password: pb.Password, IP: grpcClientIP(ctx, s.cfg.TrustProxyHeaders)}
+	token, err := s.UsersService.Login(ctx, req)
+	if errors.As(err, &usersErrors.LoginLockedError{}) {
+		logger.Warn("login locked", "error", err.Error())
+		s.metrics.Logins.WithLabelValues("locked").Inc()
+		return nil, grpcError(err)
+	}
+	if err != nil {
+		logger.Error("login error", "error", err.Error())
+		s.metrics.Logins.WithLabelValues("failure").Inc()
+		return nil, grpcError(err)
+	}
+	s.metrics.Logins.WithLabelValues("success").Inc()
+
+	logger.Info("users grpc request completed")
+	return newLoginResponse(token), nil
+}
+
+func (s *UsersServiceApp) GetUser(ctx context.Context, pb *pb.UserID) (*pb.UserResponse, error) {
+	logger := logger.FromContext(ctx).With("method", "GetUser")
+	logger.Info("users grpc request started")
+
+	callerID, err := GetUserID(ctx)
+	if err != nil {
+		logger.Error("error getting userID from ctx", "error", err.Error())
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	userID := pb.UserId
+	if userID == 0 {
+		userID = callerID
+	}
+
+	user, err := s.UsersService.GetUser(ctx, userID)
+	if err != nil {
+		logger.Error("get user error", "error", err.Error())
+		return nil, grpcError(err)
+	}
+
+	res := toUserResponse(user)
+	// The email is private, other users get the public profile only.
+	if userID != callerID {
+		res.Email = ""
+	}
+
+	logger.Info("users grpc request completed")
+	return res, nil
+}
+
+func (s *UsersServiceApp) BatchGetUsers(ctx context.Context, pb *pb.BatchGetUsersRequest) (*pb.BatchGetUsersResponse, error) {
+	logger := logger.FromContext(ctx).With("method", "BatchGetUsers")
+	logger.Info("users grpc request started")
+
+	users, err := s.UsersService.BatchGetUsers(ctx, pb.UserIds)
+	if err != nil {
+		logger.Error("batch get users error", "error", err.Error())
+		return nil, grpcError(err)
+	}
+
+	logger.Info("users grpc request completed")
+	return newBatchGetUsersResponse(users), nil
+}
+
+func (s *UsersServiceApp) UpdateUser(ctx context.Context, pb *pb.UpdateUserRequest) (*pb.UserResponse, error) {
+	logger := logger.FromContext(ctx).With("method", "UpdateUser")
+	logger.Info("users grpc request started")
+
+	userID, err := GetUserID(ctx)
+	if err != nil {
+		logger.Error("error getting userID from ctx", "error", err.Error())
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	user, err := s.UsersService.UpdateUser(ctx, &models.UserUpdateRequest{
+		Name:    pb.Name,
+		Surname: pb.Surname,
+		Email:   pb.Email,
+	}, userID)
+	if err != nil {
+		logger.Error("update user error", "error", err.Error())
+		return nil, grpcError(err)
+	}
+
+	if err = s.EventsService.PublishClientUpdated(ctx, s.cfg.UsersTopic, newClientUpdatedEvent(user)); err != nil {
+		logger.Error("update user send event error", "error", err.Error())
+		return nil, err
+	}
+
+	logger.Info("users grpc request completed")
+	return toUserResponse(user), nil
+}
+
+func newClientRegisteredEvent(userID int) *pb.ClientRegisteredEvent {
+	return &pb.ClientRegisteredEvent{UserId: int32(userID)}
+}
+
+func newLoginResponse(token string) *pb.LoginResponse {
+	return &pb.LoginResponse{Token: token}
+}
+
+func newBatchGetUsersResponse(users []*models.DbUser) *pb.BatchGetUsersResponse {
+	res := &pb.BatchGetUsersResponse{Users: make([]*pb.UserResponse, len(users))}
+	for i, user := range users {
+		res.Users[i] = toUserResponse(user)
+	}
+
+	return res
+}
+
+// GetUserID returns the user checked by the gateway, it is passed in the user_id metadata.
+func GetUserID(ctx context.Context) (int32, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0, errors.New("no metadata from incoming context")
+	}
+
+	values := md.Get("user_id")
+	if len(values) != 1 {
+		return 0, errors.New("user_id must be passed once")
+	}
+
+	userID, err := strconv.Atoi(values[0])
+	if err != nil {
+		return 0, err
+	}
+
+	return int32(userID), nil
+}
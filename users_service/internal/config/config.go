@@ -3,20 +3,59 @@ package config
 import (
 	"github.com/caarlos0/env/v8"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/logger"
+	"path"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	KafkaConfig
 	UsersServiceConfig
+	TracingConfig
+	MetricsConfig
+	HealthConfig
+	AccessLogConfig
+	LoginProtectionConfig
 }
 
 type KafkaConfig struct {
+	ProducerConfig
 	Brokers      []string `env:"KAFKA_BROKERS" envSeparator:"," envDefault:"kafka:19092"`
 	ClientsTopic string   `env:"KAFKA_CLIENTS_TOPIC" envDefault:"clients.topic"`
+	UsersTopic   string   `env:"KAFKA_USERS_TOPIC" envDefault:"users.topic"`
+	AuditTopic   string   `env:"KAFKA_AUDIT_TOPIC" envDefault:"audit.topic"`
+
+	ClientsTopicSpec TopicSpec `envPrefix:"KAFKA_CLIENTS_TOPIC_"`
+	UsersTopicSpec   TopicSpec `envPrefix:"KAFKA_USERS_TOPIC_"`
+	AuditTopicSpec   TopicSpec `envPrefix:"KAFKA_AUDIT_TOPIC_"`
+}
+
+// ProducerConfig configures BaseProducer. RequiredAcks is -1 for all replicas, 1 for the leader
+// and 0 for none.
+type ProducerConfig struct {
+	ProducerAsync          bool          `env:"KAFKA_PRODUCER_ASYNC" envDefault:"true"`
+	ProducerRequiredAcks   int           `env:"KAFKA_PRODUCER_REQUIRED_ACKS" envDefault:"-1"`
+	ProducerBatchSize      int           `env:"KAFKA_PRODUCER_BATCH_SIZE" envDefault:"100"`
+	ProducerBatchTimeout   time.Duration `env:"KAFKA_PRODUCER_BATCH_TIMEOUT" envDefault:"10ms"`
+	ProducerBufferSize     int           `env:"KAFKA_PRODUCER_BUFFER_SIZE" envDefault:"10000"`
+	ProducerEnqueueTimeout time.Duration `env:"KAFKA_PRODUCER_ENQUEUE_TIMEOUT" envDefault:"100ms"`
+	ProducerMaxRetries     int           `env:"KAFKA_PRODUCER_MAX_RETRIES" envDefault:"5"`
+	ProducerRetryBackoff   time.Duration `env:"KAFKA_PRODUCER_RETRY_BACKOFF" envDefault:"100ms"`
+}
+
+// TopicSpec describes how a topic is created and kept in sync on startup.
+// Retention and CleanupPolicy are applied only when they are set, the topic keeps the broker
+// defaults or the configs set by an operator otherwise. A negative retention keeps messages forever.
+type TopicSpec struct {
+	Partitions        int           `env:"PARTITIONS" envDefault:"3"`
+	ReplicationFactor int           `env:"REPLICATION_FACTOR" envDefault:"1"`
+	Retention         time.Duration `env:"RETENTION"`
+	CleanupPolicy     string        `env:"CLEANUP_POLICY"`
 }
 
 type UsersServiceConfig struct {
 	UsersServicePort      string `env:"USERS_SERVICE_PORT" envDefault:":8081"`
+	UsersServiceGRPCPort  string `env:"USERS_SERVICE_GRPC_PORT" envDefault:":50054"`
 	UsersServiceHost      string `env:"USERS_SERVICE_HOST" envDefault:"users-service"`
 	UsersPostgresDb       string `env:"USERS_POSTGRES_DB" envDefault:"users_db"`
 	UsersPostgresUser     string `env:"USERS_POSTGRES_USER" envDefault:"username"`
@@ -25,6 +64,68 @@ type UsersServiceConfig struct {
 	UsersPostgresHost     string `env:"USERS_POSTGRES_HOST" envDefault:"users-postgres"`
 }
 
+// TracingConfig selects where spans are exported: "otlp" sends them to TracingOTLPEndpoint,
+// "stdout" prints them and "none" only propagates the trace context.
+type TracingConfig struct {
+	TracingExporter     string  `env:"TRACING_EXPORTER" envDefault:"otlp"`
+	TracingOTLPEndpoint string  `env:"TRACING_OTLP_ENDPOINT" envDefault:"jaeger:4317"`
+	TracingSampleRatio  float64 `env:"TRACING_SAMPLE_RATIO" envDefault:"1"`
+}
+
+// MetricsConfig is the address of the /metrics endpoint, it is served apart from the API.
+type MetricsConfig struct {
+	MetricsPort string `env:"METRICS_PORT" envDefault:":9090"`
+}
+
+// HealthConfig configures the readiness checks, the gRPC health status is refreshed
+// every HealthCheckInterval.
+type HealthConfig struct {
+	HealthCheckInterval time.Duration `env:"HEALTH_CHECK_INTERVAL" envDefault:"10s"`
+	HealthCheckTimeout  time.Duration `env:"HEALTH_CHECK_TIMEOUT" envDefault:"2s"`
+}
+
+// AccessLogConfig configures the access log. AccessLogFormat is "json", "common" or "combined".
+// AccessLogSampling logs only a share of the requests to the paths matching the key as a
+// prefix or a path.Match pattern, e.g. ACCESS_LOG_SAMPLING=/get_user_info:0.5.
+// Failed and slow requests are always logged.
+// TrustProxyHeaders takes the client IP from the last X-Forwarded-For address, the one added by
+// the gateway, instead of the connection. The login guard identifies the clients the same way,
+// so it must be enabled only when users_service is reachable through the gateway alone.
+type AccessLogConfig struct {
+	AccessLogFormat      string             `env:"ACCESS_LOG_FORMAT" envDefault:"json"`
+	AccessLogSampling    map[string]float64 `env:"ACCESS_LOG_SAMPLING" envDefault:"/healthz:0,/readyz:0"`
+	SlowRequestThreshold time.Duration      `env:"SLOW_REQUEST_THRESHOLD" envDefault:"1s"`
+	TrustProxyHeaders    bool               `env:"TRUST_PROXY_HEADERS" envDefault:"false"`
+}
+
+// LoginProtectionConfig throttles password guessing. Login attempts are counted per login and
+// per client IP within LoginFailureWindow from before the password is checked until they
+// succeed, every counted attempt delays the next answer by LoginDelayStep doubled per attempt up
+// to LoginMaxDelay. LoginMaxFailures failures of a login or LoginMaxFailuresPerIP failures of an
+// IP lock it out for LoginLockoutDuration.
+type LoginProtectionConfig struct {
+	LoginMaxFailures      int           `env:"LOGIN_MAX_FAILURES" envDefault:"5"`
+	LoginMaxFailuresPerIP int           `env:"LOGIN_MAX_FAILURES_PER_IP" envDefault:"20"`
+	LoginFailureWindow    time.Duration `env:"LOGIN_FAILURE_WINDOW" envDefault:"15m"`
+	LoginLockoutDuration  time.Duration `env:"LOGIN_LOCKOUT_DURATION" envDefault:"15m"`
+	LoginDelayStep        time.Duration `env:"LOGIN_DELAY_STEP" envDefault:"100ms"`
+	LoginMaxDelay         time.Duration `env:"LOGIN_MAX_DELAY" envDefault:"3s"`
+}
+
+// SampleRate returns the share of the logged requests of the longest AccessLogSampling key
+// matching path, all requests are logged by default.
+func (c AccessLogConfig) SampleRate(p string) float64 {
+	rate, matched := 1.0, ""
+	for key, r := range c.AccessLogSampling {
+		ok, _ := path.Match(key, p)
+		if (ok || strings.HasPrefix(p, key)) && len(key) > len(matched) {
+			rate, matched = r, key
+		}
+	}
+
+	return rate
+}
+
 func NewConfig() (*Config, error) {
 	cfg := Config{}
 
@@ -1,5 +1,7 @@
 package errors
 
+import "time"
+
 type LoginError struct {
 }
 
@@ -13,3 +15,48 @@ type AlreadyRegisteredError struct {
 func (err AlreadyRegisteredError) Error() string {
 	return "User with this login already exists"
 }
+
+type UserNotFoundError struct {
+}
+
+func (err UserNotFoundError) Error() string {
+	return "User not found"
+}
+
+type ProducerBufferFullError struct {
+}
+
+func (err ProducerBufferFullError) Error() string {
+	return "Kafka producer buffer is full"
+}
+
+type ProducerClosedError struct {
+}
+
+func (err ProducerClosedError) Error() string {
+	return "Kafka producer is closed"
+}
+
+type UnknownTracingExporterError struct {
+	Exporter string
+}
+
+func (err UnknownTracingExporterError) Error() string {
+	return "Unknown tracing exporter: " + err.Exporter
+}
+
+type UnknownAccessLogFormatError struct {
+	Format string
+}
+
+func (err UnknownAccessLogFormatError) Error() string {
+	return "Unknown access log format: " + err.Format
+}
+
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (err LoginLockedError) Error() string {
+	return "Too many failed logins, try again later"
+}
@@ -2,28 +2,33 @@ package db
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/config"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/logger"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/models"
 	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/opentelemetry-go-extra/otelsql"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 
 	"github.com/uptrace/bun"
 )
 
-func InitDb(lc fx.Lifecycle, cfg *config.Config) *bun.DB {
+func InitDb(lc fx.Lifecycle, cfg *config.Config, tp trace.TracerProvider, reg prometheus.Registerer) *bun.DB {
 	dsn := fmt.Sprintf("postgres://%s:%s@%s%s/%s?sslmode=disable",
 		cfg.UsersPostgresUser, cfg.UsersPostgresPassword, cfg.UsersPostgresHost, cfg.UsersPostgresPort, cfg.UsersPostgresDb)
 
-	sqldb, err := sql.Open("pgx", dsn)
+	sqldb, err := otelsql.Open("pgx", dsn, otelsql.WithTracerProvider(tp), otelsql.WithDBSystem("postgresql"))
 	if err != nil {
 		logger.Logger.Error("open database error", "error", err.Error())
 		return nil
 	}
 
+	reg.MustRegister(collectors.NewDBStatsCollector(sqldb, cfg.UsersPostgresDb))
+
 	db := bun.NewDB(sqldb, pgdialect.New())
 
 	_, err = db.NewCreateTable().
@@ -0,0 +1,96 @@
+package health
+
+import (
+	"context"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/config"
+	"github.com/segmentio/kafka-go"
+	"github.com/uptrace/bun"
+	"go.uber.org/fx"
+	grpchealth "google.golang.org/grpc/health"
+	"sync"
+	"time"
+)
+
+// Module serves grpc.health.v1, the status follows the readiness checks of the service.
+var Module = fx.Module("health",
+	fx.Provide(
+		NewChecker,
+		grpchealth.NewServer,
+	),
+	fx.Invoke(RunHealthServer),
+)
+
+// Check is a dependency the service needs to serve requests.
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Checker runs the readiness checks of the service.
+type Checker struct {
+	checks  []Check
+	timeout time.Duration
+}
+
+func NewChecker(cfg *config.Config, db *bun.DB) *Checker {
+	return &Checker{
+		checks: []Check{
+			{Name: "postgres", Check: db.PingContext},
+			{Name: "kafka", Check: kafkaCheck(cfg.Brokers)},
+		},
+		timeout: cfg.HealthCheckTimeout,
+	}
+}
+
+// Report is the result of the readiness checks, failed checks hold their error.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Ready runs the checks concurrently, each of them is bounded by the check timeout.
+func (c *Checker) Ready(ctx context.Context) (*Report, bool) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	report := &Report{Status: "ok", Checks: make(map[string]string, len(c.checks))}
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := "ok"
+			if err := check.Check(ctx); err != nil {
+				res = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.Name] = res
+			if res != "ok" {
+				report.Status = "unavailable"
+			}
+		}()
+	}
+	wg.Wait()
+
+	return report, report.Status == "ok"
+}
+
+// kafkaCheck succeeds when any of the brokers accepts a connection.
+func kafkaCheck(brokers []string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var err error
+		for _, broker := range brokers {
+			var conn *kafka.Conn
+			if conn, err = kafka.DialContext(ctx, "tcp", broker); err == nil {
+				return conn.Close()
+			}
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,28 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+func writeReport(w http.ResponseWriter, code int, report *Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// Liveness answers /healthz, the service is alive as long as it answers.
+func Liveness(w http.ResponseWriter, _ *http.Request) {
+	writeReport(w, http.StatusOK, &Report{Status: "ok"})
+}
+
+// Readiness answers /readyz with the report of the readiness checks, 503 if any of them failed.
+func (c *Checker) Readiness(w http.ResponseWriter, r *http.Request) {
+	report, ok := c.Ready(r.Context())
+	if !ok {
+		writeReport(w, http.StatusServiceUnavailable, report)
+		return
+	}
+
+	writeReport(w, http.StatusOK, report)
+}
@@ -0,0 +1,107 @@
+package kafka
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/segmentio/kafka-go"
+	"sync"
+	"time"
+)
+
+type TopicMetrics struct {
+	Enqueued  int64
+	Delivered int64
+	Failed    int64
+	Rejected  int64
+}
+
+var producerMessagesDesc = prometheus.NewDesc("kafka_producer_messages_total",
+	"Number of messages by topic and result: enqueued, delivered, failed or rejected.",
+	[]string{"topic", "result"}, nil)
+
+// ProducerMetrics keeps per-topic producer counters fed by delivery callbacks. It is a
+// prometheus.Collector, the counters are exported as kafka_producer_messages_total.
+type ProducerMetrics struct {
+	mu     sync.Mutex
+	topics map[string]*TopicMetrics
+	writes *prometheus.HistogramVec
+}
+
+func NewProducerMetrics() *ProducerMetrics {
+	return &ProducerMetrics{
+		topics: make(map[string]*TopicMetrics),
+		writes: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "kafka_producer_write_seconds",
+			Help:    "Duration of writing a batch to kafka including retries by result.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"result"}),
+	}
+}
+
+func (m *ProducerMetrics) get(topic string) *TopicMetrics {
+	t, ok := m.topics[topic]
+	if !ok {
+		t = &TopicMetrics{}
+		m.topics[topic] = t
+	}
+
+	return t
+}
+
+func (m *ProducerMetrics) enqueued(topic string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.get(topic).Enqueued++
+}
+
+func (m *ProducerMetrics) rejected(topic string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.get(topic).Rejected++
+}
+
+func (m *ProducerMetrics) delivery(msgs []kafka.Message, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, msg := range msgs {
+		if err != nil {
+			m.get(msg.Topic).Failed++
+		} else {
+			m.get(msg.Topic).Delivered++
+		}
+	}
+}
+
+func (m *ProducerMetrics) written(d time.Duration, err error) {
+	result := "delivered"
+	if err != nil {
+		result = "failed"
+	}
+	m.writes.WithLabelValues(result).Observe(d.Seconds())
+}
+
+func (m *ProducerMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- producerMessagesDesc
+	m.writes.Describe(ch)
+}
+
+func (m *ProducerMetrics) Collect(ch chan<- prometheus.Metric) {
+	for topic, t := range m.Snapshot() {
+		for result, v := range map[string]int64{"enqueued": t.Enqueued, "delivered": t.Delivered, "failed": t.Failed, "rejected": t.Rejected} {
+			ch <- prometheus.MustNewConstMetric(producerMessagesDesc, prometheus.CounterValue, float64(v), topic, result)
+		}
+	}
+	m.writes.Collect(ch)
+}
+
+// Snapshot returns a copy of the current counters keyed by topic.
+func (m *ProducerMetrics) Snapshot() map[string]TopicMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	res := make(map[string]TopicMetrics, len(m.topics))
+	for topic, t := range m.topics {
+		res[topic] = *t
+	}
+
+	return res
+}
@@ -0,0 +1,153 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/config"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/logger"
+	"github.com/segmentio/kafka-go"
+	"strconv"
+)
+
+// ReconcileTopics creates missing topics and brings existing ones in line with their specs:
+// partitions are added if there are too few of them and retention and cleanup policy are updated
+// if they are set in the spec. Partitions are never removed, kafka does not support it.
+func ReconcileTopics(ctx context.Context, cfg *config.Config, specs map[string]config.TopicSpec) error {
+	client := &kafka.Client{Addr: kafka.TCP(cfg.Brokers...)}
+
+	req := kafka.CreateTopicsRequest{}
+	for name, spec := range specs {
+		req.Topics = append(req.Topics, kafka.TopicConfig{
+			Topic:             name,
+			NumPartitions:     spec.Partitions,
+			ReplicationFactor: spec.ReplicationFactor,
+			ConfigEntries:     configEntries(spec),
+		})
+	}
+
+	resp, err := client.CreateTopics(ctx, &req)
+	if err != nil {
+		logger.Logger.Error("kafka create topics error", "error", err.Error())
+		return err
+	}
+
+	var existing []string
+	for name, err := range resp.Errors {
+		switch {
+		case err == nil:
+			logger.Logger.Info("kafka topic created", "topic", name)
+		case errors.Is(err, kafka.TopicAlreadyExists):
+			existing = append(existing, name)
+		default:
+			logger.Logger.Error("kafka create topic error", "topic", name, "error", err.Error())
+			return err
+		}
+	}
+
+	if len(existing) == 0 {
+		return nil
+	}
+
+	if err = addPartitions(ctx, client, existing, specs); err != nil {
+		return err
+	}
+
+	return alterConfigs(ctx, client, existing, specs)
+}
+
+func addPartitions(ctx context.Context, client *kafka.Client, topics []string, specs map[string]config.TopicSpec) error {
+	meta, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: topics})
+	if err != nil {
+		logger.Logger.Error("kafka metadata error", "error", err.Error())
+		return err
+	}
+
+	req := kafka.CreatePartitionsRequest{}
+	for _, t := range meta.Topics {
+		if t.Error != nil {
+			logger.Logger.Error("kafka topic metadata error", "topic", t.Name, "error", t.Error.Error())
+			return t.Error
+		}
+
+		want := specs[t.Name].Partitions
+		switch {
+		case len(t.Partitions) < want:
+			logger.Logger.Info("kafka topic partitions added", "topic", t.Name, "from", len(t.Partitions), "to", want)
+			req.Topics = append(req.Topics, kafka.TopicPartitionsConfig{Name: t.Name, Count: int32(want)})
+		case len(t.Partitions) > want:
+			logger.Logger.Warn("kafka topic has more partitions than configured", "topic", t.Name, "partitions", len(t.Partitions), "configured", want)
+		}
+	}
+
+	if len(req.Topics) == 0 {
+		return nil
+	}
+
+	resp, err := client.CreatePartitions(ctx, &req)
+	if err != nil {
+		logger.Logger.Error("kafka create partitions error", "error", err.Error())
+		return err
+	}
+	for name, err := range resp.Errors {
+		if err != nil {
+			logger.Logger.Error("kafka create topic partitions error", "topic", name, "error", err.Error())
+			return err
+		}
+	}
+
+	return nil
+}
+
+func alterConfigs(ctx context.Context, client *kafka.Client, topics []string, specs map[string]config.TopicSpec) error {
+	req := kafka.IncrementalAlterConfigsRequest{}
+	for _, name := range topics {
+		resource := kafka.IncrementalAlterConfigsRequestResource{
+			ResourceType: kafka.ResourceTypeTopic,
+			ResourceName: name,
+		}
+		for _, entry := range configEntries(specs[name]) {
+			resource.Configs = append(resource.Configs, kafka.IncrementalAlterConfigsRequestConfig{
+				Name:            entry.ConfigName,
+				Value:           entry.ConfigValue,
+				ConfigOperation: kafka.ConfigOperationSet,
+			})
+		}
+		if len(resource.Configs) > 0 {
+			req.Resources = append(req.Resources, resource)
+		}
+	}
+	if len(req.Resources) == 0 {
+		return nil
+	}
+
+	resp, err := client.IncrementalAlterConfigs(ctx, &req)
+	if err != nil {
+		logger.Logger.Error("kafka alter topic configs error", "error", err.Error())
+		return err
+	}
+	for _, r := range resp.Resources {
+		if r.Error != nil {
+			logger.Logger.Error("kafka alter topic config error", "topic", r.ResourceName, "error", r.Error.Error())
+			return r.Error
+		}
+	}
+
+	return nil
+}
+
+// configEntries are the configs set in spec, the unset ones are left to the broker.
+func configEntries(spec config.TopicSpec) []kafka.ConfigEntry {
+	var entries []kafka.ConfigEntry
+	if spec.Retention != 0 {
+		retention := int64(-1)
+		if spec.Retention > 0 {
+			retention = spec.Retention.Milliseconds()
+		}
+		entries = append(entries, kafka.ConfigEntry{ConfigName: "retention.ms", ConfigValue: strconv.FormatInt(retention, 10)})
+	}
+	if spec.CleanupPolicy != "" {
+		entries = append(entries, kafka.ConfigEntry{ConfigName: "cleanup.policy", ConfigValue: spec.CleanupPolicy})
+	}
+
+	return entries
+}
@@ -1,12 +1,56 @@
 package logger
 
 import (
+	"context"
 	"log/slog"
 	"os"
 )
 
+const (
+	// RequestIDHeader is the HTTP header with the ID of the request.
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDKey passes the ID of the request in gRPC metadata and Kafka headers.
+	RequestIDKey = "x-request-id"
+)
+
 var Logger = NewLogger()
 
 func NewLogger() *slog.Logger {
 	return slog.New(slog.NewJSONHandler(os.Stdout, nil))
 }
+
+type requestIDKey struct{}
+
+type userIDKey struct{}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func WithUserID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, id)
+}
+
+func UserID(ctx context.Context) string {
+	id, _ := ctx.Value(userIDKey{}).(string)
+	return id
+}
+
+// FromContext returns Logger with the request_id and user_id of the request in ctx,
+// so that the logs of one request can be found in every service.
+func FromContext(ctx context.Context) *slog.Logger {
+	l := Logger
+	if id := RequestID(ctx); id != "" {
+		l = l.With("request_id", id)
+	}
+	if id := UserID(ctx); id != "" {
+		l = l.With("user_id", id)
+	}
+
+	return l
+}
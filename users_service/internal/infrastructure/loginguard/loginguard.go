@@ -0,0 +1,190 @@
+package loginguard
+
+import (
+	"context"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/config"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/errors"
+	"go.uber.org/fx"
+	"time"
+)
+
+// Module counts the login attempts in memory.
+var Module = fx.Module("loginguard",
+	fx.Provide(
+		NewMemoryStore,
+		func(s *MemoryStore) Store {
+			return s
+		},
+		NewGuard,
+	),
+)
+
+const (
+	LockedByLogin = "login"
+	LockedByIP    = "ip"
+)
+
+// Attempts are the attempts of a key counted since Since, the key is locked until LockedUntil.
+type Attempts struct {
+	Count       int
+	Since       time.Time
+	LockedUntil time.Time
+}
+
+// Store keeps the attempts by key, the attempts older than the window are forgotten.
+type Store interface {
+	// Take counts an attempt of key unless the key is locked or has limit attempts within
+	// window, it returns the attempts and whether this one was counted. A non-positive limit
+	// does not limit the attempts.
+	Take(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (Attempts, bool, error)
+	// Lock locks key until until and reports whether it was not locked already.
+	Lock(ctx context.Context, key string, until, now time.Time) (bool, error)
+	// Forgive uncounts an attempt of key.
+	Forgive(ctx context.Context, key string) error
+	Reset(ctx context.Context, key string) error
+}
+
+// Lockout is a login or an IP locked by the last failure.
+type Lockout struct {
+	LockedBy string
+	Failures int
+	Until    time.Time
+}
+
+type counted struct {
+	key      string
+	lockedBy string
+	limit    int
+	count    int
+}
+
+// Attempt is a login attempt counted by Guard.Begin, it is finished with Guard.Fail,
+// Guard.Succeed or Guard.Cancel.
+type Attempt struct {
+	// Delay is the time to wait before the password is checked.
+	Delay time.Duration
+	keys  []counted
+}
+
+// Guard tracks the login attempts of every login and client IP.
+type Guard struct {
+	store Store
+	cfg   config.LoginProtectionConfig
+}
+
+func NewGuard(cfg *config.Config, store Store) *Guard {
+	return &Guard{store: store, cfg: cfg.LoginProtectionConfig}
+}
+
+// Begin counts the attempt before the password is checked, so that concurrent attempts can not
+// get past the limits. It returns errors.LoginLockedError while the login or the IP is locked
+// or has used up its attempts. The delay grows with the attempts of both, whether the login
+// exists or not.
+func (g *Guard) Begin(ctx context.Context, login, ip string, now time.Time) (*Attempt, error) {
+	limits := []counted{{key: loginKey(login), lockedBy: LockedByLogin, limit: g.cfg.LoginMaxFailures}}
+	if ip != "" {
+		limits = append(limits, counted{key: "ip:" + ip, lockedBy: LockedByIP, limit: g.cfg.LoginMaxFailuresPerIP})
+	}
+
+	attempt, prior := &Attempt{}, 0
+	for _, c := range limits {
+		a, ok, err := g.store.Take(ctx, c.key, c.limit, g.cfg.LoginFailureWindow, now)
+		if err == nil && !ok {
+			err = errors.LoginLockedError{RetryAfter: g.retryAfter(a, now)}
+		}
+		if err != nil {
+			g.forgive(ctx, attempt.keys)
+			return nil, err
+		}
+
+		c.count = a.Count
+		attempt.keys = append(attempt.keys, c)
+		prior = max(prior, a.Count-1)
+	}
+	attempt.Delay = g.delay(prior)
+
+	return attempt, nil
+}
+
+// Fail locks the login or the IP whose attempts reached the limit with this failure. A lockout
+// is returned once, by the failure that locked the key.
+func (g *Guard) Fail(ctx context.Context, attempt *Attempt, now time.Time) ([]Lockout, error) {
+	var lockouts []Lockout
+	for _, c := range attempt.keys {
+		if c.limit <= 0 || c.count < c.limit {
+			continue
+		}
+
+		until := now.Add(g.cfg.LoginLockoutDuration)
+		locked, err := g.store.Lock(ctx, c.key, until, now)
+		if err != nil {
+			return lockouts, err
+		}
+		if locked {
+			lockouts = append(lockouts, Lockout{LockedBy: c.lockedBy, Failures: c.count, Until: until})
+		}
+	}
+
+	return lockouts, nil
+}
+
+// Succeed forgets the attempts of the login. Only this attempt of the IP is uncounted, so that
+// logging in to an own account does not reset the guessing of others.
+func (g *Guard) Succeed(ctx context.Context, attempt *Attempt) error {
+	for _, c := range attempt.keys {
+		if c.lockedBy == LockedByLogin {
+			if err := g.store.Reset(ctx, c.key); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := g.store.Forgive(ctx, c.key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Cancel uncounts an attempt whose password could not be checked.
+func (g *Guard) Cancel(ctx context.Context, attempt *Attempt) error {
+	return g.forgive(ctx, attempt.keys)
+}
+
+func (g *Guard) forgive(ctx context.Context, keys []counted) error {
+	for _, c := range keys {
+		if err := g.store.Forgive(ctx, c.key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// retryAfter is the time until the key is unlocked, or until its attempts leave the window
+// when they are used up by the attempts still being checked.
+func (g *Guard) retryAfter(a Attempts, now time.Time) time.Duration {
+	if a.LockedUntil.After(now) {
+		return a.LockedUntil.Sub(now)
+	}
+
+	return max(a.Since.Add(g.cfg.LoginFailureWindow).Sub(now), time.Second)
+}
+
+// delay is LoginDelayStep doubled per prior attempt up to LoginMaxDelay.
+func (g *Guard) delay(attempts int) time.Duration {
+	if attempts <= 0 {
+		return 0
+	}
+
+	d := g.cfg.LoginDelayStep
+	for i := 1; i < attempts && d < g.cfg.LoginMaxDelay; i++ {
+		d *= 2
+	}
+
+	return min(d, g.cfg.LoginMaxDelay)
+}
+
+func loginKey(login string) string {
+	return "login:" + login
+}
@@ -0,0 +1,121 @@
+package loginguard
+
+import (
+	"context"
+	"go.uber.org/fx"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the forgotten attempts are dropped.
+const sweepInterval = time.Minute
+
+type entry struct {
+	Attempts
+	window time.Duration
+}
+
+// expired reports whether the attempts are out of the window and the key is not locked.
+func (e *entry) expired(now time.Time) bool {
+	return now.Sub(e.Since) > e.window && !e.LockedUntil.After(now)
+}
+
+// MemoryStore keeps the attempts of one users_service instance in memory.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+func NewMemoryStore(lc fx.Lifecycle) *MemoryStore {
+	s := &MemoryStore{entries: make(map[string]*entry)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go s.sweep(ctx)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+
+	return s
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit int, window time.Duration, now time.Time) (Attempts, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || e.expired(now) {
+		e = &entry{Attempts: Attempts{Since: now}}
+		s.entries[key] = e
+	}
+	if e.LockedUntil.After(now) || (limit > 0 && e.Count >= limit) {
+		return e.Attempts, false, nil
+	}
+	e.Count++
+	e.window = window
+
+	return e.Attempts, true, nil
+}
+
+// Lock locks the key and starts counting its attempts anew once it is unlocked.
+func (s *MemoryStore) Lock(_ context.Context, key string, until, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		e = &entry{}
+		s.entries[key] = e
+	}
+	if e.LockedUntil.After(now) {
+		return false, nil
+	}
+	e.Attempts = Attempts{Since: until, LockedUntil: until}
+
+	return true, nil
+}
+
+func (s *MemoryStore) Forgive(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[key]; ok && e.Count > 0 {
+		e.Count--
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+
+	return nil
+}
+
+func (s *MemoryStore) sweep(ctx context.Context) {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for key, e := range s.entries {
+				if e.expired(now) {
+					delete(s.entries, key)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// GRPCMetrics records the rate, errors and duration of the handled gRPC calls per method.
+type GRPCMetrics struct {
+	handled  *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func NewGRPCMetrics(reg prometheus.Registerer) *GRPCMetrics {
+	m := &GRPCMetrics{
+		handled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_handled_total",
+			Help: "Number of handled gRPC calls by method and status code.",
+		}, []string{"method", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_handling_seconds",
+			Help:    "Duration of handled gRPC calls by method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+	}
+	reg.MustRegister(m.handled, m.duration)
+
+	return m
+}
+
+func (m *GRPCMetrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		m.handled.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+		m.duration.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+
+		return resp, err
+	}
+}
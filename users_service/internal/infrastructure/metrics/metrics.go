@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/fx"
+	"net/http"
+)
+
+// Module serves the metrics of the service on MetricsPort at /metrics.
+var Module = fx.Module("metrics",
+	fx.Provide(
+		NewRegistry,
+		func(r *prometheus.Registry) prometheus.Registerer {
+			return r
+		},
+		NewHTTPMetrics,
+		NewGRPCMetrics,
+		NewUsersMetrics,
+	),
+	fx.Invoke(RunServer),
+)
+
+func NewRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return reg
+}
+
+func RunServer(lc fx.Lifecycle, reg *prometheus.Registry, cfg *config.Config) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{Registry: reg}))
+	server := &http.Server{Addr: cfg.MetricsPort, Handler: mux}
+
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go func() {
+				if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					panic(err)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return server.Shutdown(ctx)
+		},
+	})
+}
+
+// UsersMetrics counts the registrations and the logins by their result.
+type UsersMetrics struct {
+	Registrations prometheus.Counter
+	Logins        *prometheus.CounterVec
+}
+
+func NewUsersMetrics(reg prometheus.Registerer) *UsersMetrics {
+	m := &UsersMetrics{
+		Registrations: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "users_registrations_total",
+			Help: "Number of registered users.",
+		}),
+		Logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "users_logins_total",
+			Help: "Number of logins by result: success, failure or locked.",
+		}, []string{"result"}),
+	}
+	reg.MustRegister(m.Registrations, m.Logins)
+
+	return m
+}
@@ -29,12 +29,9 @@ type RegisterRequest struct {
 	Password string `json:"password"`
 }
 
+// GetLoginRequest is the login of a client, IP is its address taken from the request.
 type GetLoginRequest struct {
 	Login    string `json:"login"`
 	Password string `json:"password"`
-}
-
-type ClientUpdate struct {
-	UserId int       `bun:"user_id" json:"user_id"`
-	Time   time.Time `bun:"time" json:"time"`
+	IP       string `json:"-"`
 }
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	stdErrors "errors"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/errors"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/logger"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/models"
@@ -16,84 +18,124 @@ func NewUsersRepository(db *bun.DB) *UsersRepository {
 	return &UsersRepository{db: db}
 }
 
-func (r *UsersRepository) Register(userInfo *models.DbUser) (int, error) {
+func (r *UsersRepository) Register(ctx context.Context, userInfo *models.DbUser) (int, error) {
 	exists, err := r.db.NewSelect().
 		Model((*models.DbUser)(nil)).
 		Where("login = ?", userInfo.Login).
-		Exists(context.Background())
+		Exists(ctx)
 	if err != nil {
-		logger.Logger.Error("exists check register db error", "error", err.Error())
+		logger.FromContext(ctx).Error("exists check register db error", "error", err.Error())
 		return 0, err
 	}
 	if exists {
-		logger.Logger.Error(errors.AlreadyRegisteredError{}.Error())
+		logger.FromContext(ctx).Error(errors.AlreadyRegisteredError{}.Error())
 		return 0, errors.AlreadyRegisteredError{}
 	}
 
 	var id int
-	_, err = r.db.NewInsert().Model(userInfo).Returning("id").Exec(context.Background(), &id)
+	_, err = r.db.NewInsert().Model(userInfo).Returning("id").Exec(ctx, &id)
 	if err != nil {
-		logger.Logger.Error("insert register db error", "error", err.Error())
+		logger.FromContext(ctx).Error("insert register db error", "error", err.Error())
 		return 0, err
 	}
 
 	return id, nil
 }
 
-func (r *UsersRepository) Login(userInfo *models.GetLoginRequest) error {
-	exists, err := r.db.NewSelect().
-		Model((*models.DbUser)(nil)).
-		Where("login = ? and password = ?", userInfo.Login, userInfo.Password).
-		Exists(context.Background())
-	if err != nil {
-		logger.Logger.Error("exists check register db error", "error", err.Error())
-		return err
+func (r *UsersRepository) GetUserByLogin(ctx context.Context, login string) (*models.DbUser, error) {
+	var user models.DbUser
+	err := r.db.NewSelect().
+		Model(&user).
+		Where("login = ?", login).
+		Scan(ctx)
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return nil, errors.UserNotFoundError{}
 	}
-
-	if !exists {
-		logger.Logger.Error(errors.LoginError{}.Error())
-		return errors.LoginError{}
+	if err != nil {
+		logger.FromContext(ctx).Error("get user by login db error", "error", err.Error())
+		return nil, err
 	}
 
-	return nil
+	return &user, nil
 }
 
-func (r *UsersRepository) UpdateUserInfo(userInfo *models.DbUser, login string) error {
-	_, err := r.db.NewUpdate().Model(userInfo).Where("login = ?", login).OmitZero().Exec(context.Background())
+func (r *UsersRepository) UpdateUserInfo(ctx context.Context, userInfo *models.DbUser, login string) error {
+	_, err := r.db.NewUpdate().Model(userInfo).Where("login = ?", login).OmitZero().Exec(ctx)
 	if err != nil {
-		logger.Logger.Error("update user info db error", "error", err.Error())
+		logger.FromContext(ctx).Error("update user info db error", "error", err.Error())
 		return err
 	}
 
 	return nil
 }
 
-func (r *UsersRepository) GetUserInfo(userLogin string) (*models.DbUser, error) {
+func (r *UsersRepository) GetUserInfo(ctx context.Context, userLogin string) (*models.DbUser, error) {
 	var user models.DbUser
 	err := r.db.NewSelect().
 		Model(&user).
 		Where("login = ?", userLogin).
-		Scan(context.Background())
+		Scan(ctx)
 
 	if err != nil {
-		logger.Logger.Error("get user info db error", "error", err.Error())
+		logger.FromContext(ctx).Error("get user info db error", "error", err.Error())
 		return nil, err
 	}
 
 	return &user, nil
 }
 
-func (r *UsersRepository) GetUserID(login string) (int, error) {
+func (r *UsersRepository) GetUserByID(ctx context.Context, id int) (*models.DbUser, error) {
 	var user models.DbUser
 	err := r.db.NewSelect().
 		Model(&user).
-		Where("login = ?", login).
-		Scan(context.Background())
+		Where("id = ?", id).
+		Scan(ctx)
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		logger.FromContext(ctx).Info(errors.UserNotFoundError{}.Error(), "user_id", id)
+		return nil, errors.UserNotFoundError{}
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("get user by id db error", "error", err.Error())
+		return nil, err
+	}
+
+	return &user, nil
+}
 
+func (r *UsersRepository) GetUsersByIDs(ctx context.Context, ids []int) ([]*models.DbUser, error) {
+	var users []*models.DbUser
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	err := r.db.NewSelect().
+		Model(&users).
+		Where("id IN (?)", bun.In(ids)).
+		Scan(ctx)
 	if err != nil {
-		logger.Logger.Error("get user id db error", "error", err.Error())
-		return 0, err
+		logger.FromContext(ctx).Error("get users by ids db error", "error", err.Error())
+		return nil, err
 	}
 
-	return user.Id, nil
+	return users, nil
+}
+
+func (r *UsersRepository) UpdateUserByID(ctx context.Context, userInfo *models.DbUser) error {
+	res, err := r.db.NewUpdate().Model(userInfo).WherePK().OmitZero().Exec(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("update user by id db error", "error", err.Error())
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		logger.FromContext(ctx).Error("update user by id rows affected error", "error", err.Error())
+		return err
+	}
+	if rows == 0 {
+		logger.FromContext(ctx).Info(errors.UserNotFoundError{}.Error(), "user_id", userInfo.Id)
+		return errors.UserNotFoundError{}
+	}
+
+	return nil
 }
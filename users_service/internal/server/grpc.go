@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"errors"
+	pb "github.com/grigorovskiiy/soa-hse/protos"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/application"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/config"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/metrics"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"net"
+)
+
+func NewGRPCServer(s *application.UsersServiceApp, cfg *config.Config, tp trace.TracerProvider, m *metrics.GRPCMetrics,
+	hs *grpchealth.Server) (*grpc.Server, net.Listener, error) {
+	lis, err := net.Listen("tcp", cfg.UsersServiceGRPCPort)
+	if err != nil {
+		logger.Logger.Error("failed to listen", "error", err.Error())
+		return nil, nil, err
+	}
+
+	grpcServer := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithTracerProvider(tp))),
+		grpc.ChainUnaryInterceptor(requestContext, m.UnaryServerInterceptor()))
+	pb.RegisterUsersServiceServer(grpcServer, s)
+	healthpb.RegisterHealthServer(grpcServer, hs)
+
+	return grpcServer, lis, nil
+}
+
+// requestContext puts the request ID and the user passed by the gateway in the metadata into
+// the context of the call, so that they are attached to the logs and the events it sends.
+func requestContext(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get(logger.RequestIDKey); len(v) > 0 {
+		ctx = logger.WithRequestID(ctx, v[0])
+	}
+	if v := md.Get("user_id"); len(v) == 1 && v[0] != "" {
+		ctx = logger.WithUserID(ctx, v[0])
+	}
+
+	return handler(ctx, req)
+}
+
+func RunGRPCServer(lc fx.Lifecycle, grpcServer *grpc.Server, listener net.Listener) error {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				if err := grpcServer.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+					panic(err)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			grpcServer.GracefulStop()
+			return nil
+		},
+	})
+
+	return nil
+}
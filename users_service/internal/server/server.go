@@ -5,23 +5,51 @@ import (
 	"errors"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/application"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/config"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/health"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/metrics"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 	"net/http"
 )
 
-func NewServer(app *application.UsersApp, cfg *config.Config) *http.Server {
+func NewServer(app *application.UsersApp, cfg *config.Config, tp trace.TracerProvider, m *metrics.HTTPMetrics,
+	checker *health.Checker) (*http.Server, error) {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/register", http.HandlerFunc(app.Register))
 	mux.HandleFunc("/login", http.HandlerFunc(app.Login))
 	mux.HandleFunc("/get_user_info", http.HandlerFunc(app.GetUserInfo))
 	mux.HandleFunc("/update_user_info", http.HandlerFunc(app.UpdateUserInfo))
+	mux.HandleFunc("GET /healthz", health.Liveness)
+	mux.HandleFunc("GET /readyz", checker.Readiness)
 
-	return &http.Server{
-		Addr:    cfg.UsersServicePort,
-		Handler: mux,
+	accessLog, err := accessLogMiddleware(cfg.AccessLogConfig)
+	if err != nil {
+		return nil, err
 	}
 
+	return &http.Server{
+		Addr: cfg.UsersServicePort,
+		Handler: otelhttp.NewHandler(requestIDMiddleware(m.Middleware(accessLog(mux))), "users-service",
+			otelhttp.WithTracerProvider(tp),
+			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+				return r.Method + " " + r.URL.Path
+			})),
+	}, nil
+}
+
+// requestIDMiddleware puts the X-Request-ID set by the gateway into the context of the request.
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := r.Header.Get(logger.RequestIDHeader); id != "" {
+			r = r.WithContext(logger.WithRequestID(r.Context(), id))
+			w.Header().Set(logger.RequestIDHeader, id)
+		}
+
+		next.ServeHTTP(w, r)
+	})
 }
 
 func RunServer(lc fx.Lifecycle, server *http.Server) error {
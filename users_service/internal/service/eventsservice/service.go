@@ -2,11 +2,27 @@ package eventsservice
 
 import (
 	"context"
-	"encoding/json"
+	"github.com/google/uuid"
+	pb "github.com/grigorovskiiy/soa-hse/protos"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/config"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/kafka"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/logger"
 	kafkaGo "github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"strconv"
+)
+
+const (
+	ContentTypeHeader  = "content-type"
+	EventTypeHeader    = "event-type"
+	EventVersionHeader = "event-version"
+
+	ContentTypeProtobuf = "application/x-protobuf"
+	// EventVersion is the version of pb.EventEnvelope, version 1 was the plain JSON payload.
+	EventVersion = 2
+
+	producerName = "users_service"
 )
 
 type KafkaService struct {
@@ -14,45 +30,85 @@ type KafkaService struct {
 }
 
 func NewKafkaService(cfg *config.Config, producer *kafka.BaseProducer) (*KafkaService, error) {
-	client := kafkaGo.Client{
-		Addr: kafkaGo.TCP(cfg.Brokers...),
-	}
-
-	topicsReq := CreateTopicsReq(cfg)
-	_, err := client.CreateTopics(context.Background(), &topicsReq)
-	if err != nil {
-		logger.Logger.Error("error creating topics", "error", err.Error())
+	if err := kafka.ReconcileTopics(context.Background(), cfg, TopicSpecs(cfg)); err != nil {
+		logger.Logger.Error("error reconciling topics", "error", err.Error())
 		return nil, err
 	}
 
 	return &KafkaService{producer: producer}, nil
 }
 
-func (s *KafkaService) SendEvent(ctx context.Context, topic string, upd any) error {
-	msg, err := json.Marshal(upd)
+func (s *KafkaService) PublishClientRegistered(ctx context.Context, topic string, event *pb.ClientRegisteredEvent) error {
+	env := NewEnvelope(pb.EventType_CLIENT_REGISTERED)
+	env.Payload = &pb.EventEnvelope_ClientRegistered{ClientRegistered: event}
+
+	return s.publish(ctx, topic, event.UserId, env)
+}
+
+func (s *KafkaService) PublishClientUpdated(ctx context.Context, topic string, event *pb.ClientUpdatedEvent) error {
+	env := NewEnvelope(pb.EventType_CLIENT_UPDATED)
+	env.Payload = &pb.EventEnvelope_ClientUpdated{ClientUpdated: event}
+
+	return s.publish(ctx, topic, event.UserId, env)
+}
+
+// PublishLoginLocked sends the audit event of a lockout keyed by the locked login or IP.
+func (s *KafkaService) PublishLoginLocked(ctx context.Context, topic string, event *pb.LoginLockedEvent) error {
+	env := NewEnvelope(pb.EventType_LOGIN_LOCKED)
+	env.Payload = &pb.EventEnvelope_LoginLocked{LoginLocked: event}
+
+	key := event.Login
+	if event.LockedBy == "ip" {
+		key = event.Ip
+	}
+
+	return s.publishKey(ctx, topic, []byte(key), env)
+}
+
+// publish sends the envelope keyed by key, so that events with the same key stay in one
+// partition and keep their order.
+func (s *KafkaService) publish(ctx context.Context, topic string, key int32, env *pb.EventEnvelope) error {
+	return s.publishKey(ctx, topic, []byte(strconv.Itoa(int(key))), env)
+}
+
+func (s *KafkaService) publishKey(ctx context.Context, topic string, key []byte, env *pb.EventEnvelope) error {
+	msg, err := proto.Marshal(env)
 	if err != nil {
-		logger.Logger.Error("upd json marshal error", "error", err.Error())
+		logger.FromContext(ctx).Error("event proto marshal error", "error", err.Error())
 		return err
 	}
 
 	if err := s.producer.Produce(ctx, topic, &kafkaGo.Message{
+		Key:   key,
 		Value: msg,
+		Headers: []kafkaGo.Header{
+			{Key: ContentTypeHeader, Value: []byte(ContentTypeProtobuf)},
+			{Key: EventTypeHeader, Value: []byte(env.Type.String())},
+			{Key: EventVersionHeader, Value: []byte(strconv.Itoa(int(env.Version)))},
+		},
 	}); err != nil {
-		logger.Logger.Error("kafka produce error", "error", err.Error())
+		logger.FromContext(ctx).Error("kafka produce error", "error", err.Error())
 		return err
 	}
 
 	return nil
 }
 
-func CreateTopicsReq(cfg *config.Config) kafkaGo.CreateTopicsRequest {
-	return kafkaGo.CreateTopicsRequest{
-		Topics: []kafkaGo.TopicConfig{
-			{
-				Topic:             cfg.ClientsTopic,
-				NumPartitions:     1,
-				ReplicationFactor: 1,
-			},
-		},
+// NewEnvelope returns an envelope of the current version without a payload.
+func NewEnvelope(eventType pb.EventType) *pb.EventEnvelope {
+	return &pb.EventEnvelope{
+		EventId:    uuid.NewString(),
+		Type:       eventType,
+		Version:    EventVersion,
+		OccurredAt: timestamppb.Now(),
+		Producer:   producerName,
+	}
+}
+
+func TopicSpecs(cfg *config.Config) map[string]config.TopicSpec {
+	return map[string]config.TopicSpec{
+		cfg.ClientsTopic: cfg.ClientsTopicSpec,
+		cfg.UsersTopic:   cfg.UsersTopicSpec,
+		cfg.AuditTopic:   cfg.AuditTopicSpec,
 	}
 }
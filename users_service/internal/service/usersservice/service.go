@@ -1,35 +1,59 @@
 package usersservice
 
 import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	stdErrors "errors"
 	"github.com/golang-jwt/jwt/v5"
+	pb "github.com/grigorovskiiy/soa-hse/protos"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/config"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/errors"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/logger"
+	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/loginguard"
 	"github.com/grigorovskiiy/soa-hse/users_service/internal/infrastructure/models"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"time"
 )
 
 var secretKey = []byte("secret-key")
 
+// dummyPassword is the digest compared with the password of a login that does not exist.
+var dummyPassword = sha256.Sum256([]byte("dummy-password"))
+
 type Repository interface {
-	Register(*models.DbUser) (int, error)
-	Login(*models.GetLoginRequest) error
-	UpdateUserInfo(*models.DbUser, string) error
-	GetUserInfo(string) (*models.DbUser, error)
-	GetUserID(string) (int, error)
+	Register(context.Context, *models.DbUser) (int, error)
+	UpdateUserInfo(context.Context, *models.DbUser, string) error
+	GetUserInfo(context.Context, string) (*models.DbUser, error)
+	GetUserByLogin(context.Context, string) (*models.DbUser, error)
+	GetUserByID(context.Context, int) (*models.DbUser, error)
+	GetUsersByIDs(context.Context, []int) ([]*models.DbUser, error)
+	UpdateUserByID(context.Context, *models.DbUser) error
+}
+
+type EventsService interface {
+	PublishLoginLocked(context.Context, string, *pb.LoginLockedEvent) error
 }
 
 type UService struct {
 	repository Repository
+	events     EventsService
+	guard      *loginguard.Guard
+	cfg        *config.Config
 }
 
-func NewUService(repository Repository) *UService {
+func NewUService(repository Repository, events EventsService, guard *loginguard.Guard, cfg *config.Config) *UService {
 	return &UService{
 		repository: repository,
+		events:     events,
+		guard:      guard,
+		cfg:        cfg,
 	}
 }
 
-func (a *UService) Register(req *models.RegisterRequest) (int, error) {
+func (a *UService) Register(ctx context.Context, req *models.RegisterRequest) (int, error) {
 	userInfo := models.DbUser{
 		Email:     req.Email,
 		Login:     req.Login,
@@ -38,43 +62,123 @@ func (a *UService) Register(req *models.RegisterRequest) (int, error) {
 		UpdatedAt: time.Now(),
 	}
 
-	id, err := a.repository.Register(&userInfo)
+	id, err := a.repository.Register(ctx, &userInfo)
 	if err != nil {
-		logger.Logger.Error("register user info error", "error", err.Error())
+		logger.FromContext(ctx).Error("register user info error", "error", err.Error())
 		return 0, err
 	}
 
 	return id, nil
 }
 
-func (a *UService) Login(req *models.GetLoginRequest) (string, error) {
-	if err := a.repository.Login(req); err != nil {
-		logger.Logger.Error("login error", "error", err.Error())
+// Login is counted and throttled by the guard before the password is checked, and the password
+// check takes the same time whether the login exists or not.
+func (a *UService) Login(ctx context.Context, req *models.GetLoginRequest) (string, error) {
+	attempt, err := a.guard.Begin(ctx, req.Login, req.IP, time.Now())
+	if err != nil {
+		logger.FromContext(ctx).Warn("login guard rejected login", "error", err.Error(), "login", req.Login, "ip", req.IP)
+		return "", err
+	}
+	if err = sleep(ctx, attempt.Delay); err != nil {
 		return "", err
 	}
 
-	userID, err := a.repository.GetUserID(req.Login)
+	user, err := a.checkPassword(ctx, req)
+	if stdErrors.As(err, &errors.LoginError{}) {
+		logger.FromContext(ctx).Error("login error", "error", err.Error())
+		a.loginFailed(ctx, req, attempt)
+		return "", err
+	}
 	if err != nil {
-		logger.Logger.Error("get user id error", "error", err.Error())
+		logger.FromContext(ctx).Error("login error", "error", err.Error())
+		if cancelErr := a.guard.Cancel(ctx, attempt); cancelErr != nil {
+			logger.FromContext(ctx).Error("login guard cancel error", "error", cancelErr.Error())
+		}
 		return "", err
 	}
 
+	if err = a.guard.Succeed(ctx, attempt); err != nil {
+		logger.FromContext(ctx).Error("login guard reset error", "error", err.Error())
+	}
+
 	claims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"login":    req.Login,
 		"password": req.Password,
-		"user_id":  userID,
+		"user_id":  user.Id,
 	})
 
 	token, err := claims.SignedString(secretKey)
 	if err != nil {
-		logger.Logger.Error("signing jwt error", "error", err.Error())
+		logger.FromContext(ctx).Error("signing jwt error", "error", err.Error())
 		return "", nil
 	}
 
 	return token, nil
 }
 
-func (a *UService) UpdateUserInfo(req *models.UserUpdateRequest, login string) error {
+// checkPassword compares the SHA-256 digests of the passwords in constant time, a missing login
+// is compared with dummyPassword and fails with the same errors.LoginError.
+func (a *UService) checkPassword(ctx context.Context, req *models.GetLoginRequest) (*models.DbUser, error) {
+	user, err := a.repository.GetUserByLogin(ctx, req.Login)
+	if err != nil && !stdErrors.As(err, &errors.UserNotFoundError{}) {
+		return nil, err
+	}
+
+	want := dummyPassword
+	if user != nil {
+		want = sha256.Sum256([]byte(user.Password))
+	}
+	got := sha256.Sum256([]byte(req.Password))
+
+	if subtle.ConstantTimeCompare(got[:], want[:]) != 1 || user == nil {
+		return nil, errors.LoginError{}
+	}
+
+	return user, nil
+}
+
+// loginFailed audits the lockouts caused by the failed attempt.
+func (a *UService) loginFailed(ctx context.Context, req *models.GetLoginRequest, attempt *loginguard.Attempt) {
+	lockouts, err := a.guard.Fail(ctx, attempt, time.Now())
+	if err != nil {
+		logger.FromContext(ctx).Error("login guard fail error", "error", err.Error())
+	}
+
+	for _, l := range lockouts {
+		logger.FromContext(ctx).Warn("login locked out", "audit", true, "locked_by", l.LockedBy,
+			"login", req.Login, "ip", req.IP, "failures", l.Failures, "locked_until", l.Until)
+
+		event := &pb.LoginLockedEvent{
+			Login:       req.Login,
+			Ip:          req.IP,
+			Failures:    int32(l.Failures),
+			LockedUntil: timestamppb.New(l.Until),
+			LockedBy:    l.LockedBy,
+		}
+		if err = a.events.PublishLoginLocked(ctx, a.cfg.AuditTopic, event); err != nil {
+			logger.FromContext(ctx).Error("login locked send event error", "error", err.Error())
+		}
+	}
+}
+
+// sleep waits for d unless ctx is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (a *UService) UpdateUserInfo(ctx context.Context, req *models.UserUpdateRequest, login string) error {
 	userInfo := models.DbUser{
 		Email:     req.Email,
 		UpdatedAt: time.Now(),
@@ -82,20 +186,62 @@ func (a *UService) UpdateUserInfo(req *models.UserUpdateRequest, login string) e
 		Surname:   req.Surname,
 	}
 
-	if err := a.repository.UpdateUserInfo(&userInfo, login); err != nil {
-		logger.Logger.Error("update user info error", "error", err.Error())
+	if err := a.repository.UpdateUserInfo(ctx, &userInfo, login); err != nil {
+		logger.FromContext(ctx).Error("update user info error", "error", err.Error())
 		return err
 	}
 
 	return nil
 }
 
-func (a *UService) GetUserInfo(login string) (*models.DbUser, error) {
-	userInfo, err := a.repository.GetUserInfo(login)
+func (a *UService) GetUserInfo(ctx context.Context, login string) (*models.DbUser, error) {
+	userInfo, err := a.repository.GetUserInfo(ctx, login)
 	if err != nil {
-		logger.Logger.Error("get user info error", "error", err.Error())
-		return nil, status.Errorf(codes.NotFound, err.Error())
+		logger.FromContext(ctx).Error("get user info error", "error", err.Error())
+		return nil, status.Error(codes.NotFound, err.Error())
 	}
 
 	return userInfo, nil
 }
+
+func (a *UService) GetUser(ctx context.Context, id int32) (*models.DbUser, error) {
+	user, err := a.repository.GetUserByID(ctx, int(id))
+	if err != nil {
+		logger.FromContext(ctx).Error("get user error", "error", err.Error())
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (a *UService) BatchGetUsers(ctx context.Context, ids []int32) ([]*models.DbUser, error) {
+	userIDs := make([]int, len(ids))
+	for i, id := range ids {
+		userIDs[i] = int(id)
+	}
+
+	users, err := a.repository.GetUsersByIDs(ctx, userIDs)
+	if err != nil {
+		logger.FromContext(ctx).Error("batch get users error", "error", err.Error())
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (a *UService) UpdateUser(ctx context.Context, req *models.UserUpdateRequest, id int32) (*models.DbUser, error) {
+	userInfo := models.DbUser{
+		Id:        int(id),
+		Email:     req.Email,
+		UpdatedAt: time.Now(),
+		Name:      req.Name,
+		Surname:   req.Surname,
+	}
+
+	if err := a.repository.UpdateUserByID(ctx, &userInfo); err != nil {
+		logger.FromContext(ctx).Error("update user error", "error", err.Error())
+		return nil, err
+	}
+
+	return a.GetUser(ctx, id)
+}